@@ -0,0 +1,215 @@
+// Command validate validates the records of a JSON, NDJSON or CSV file
+// against a rule definition file.
+//
+// Usage:
+//
+//	validate -rules rules.yaml [-format json|ndjson|csv] [-columns name=type,...] [-breached path] [-max-failures n] [-report text|json|markdown] [file]
+//
+// The rule file is a JSON or YAML document as read by validation.LoadFile.
+// The input is read from file, or from the standard input when file is
+// omitted; its format is guessed from the file extension unless -format is
+// given. JSON input is either one object or an array of objects, CSV input
+// must start with a header row naming the attributes. Empty CSV cells are
+// missing values, and cells of columns whose rules declare integer, float or
+// bool are converted; -columns sets the type of other columns, as in
+// "-columns age=integer,score=number,active=boolean". The uncompromised
+// requirement of the password rule checks the breached passwords of
+// -breached: a directory of hash prefix files, read as a
+// validation.PrefixDir, or a file of SHA-1 hashes. Records are validated
+// one at a time, so inputs of any size can be checked; -max-failures stops
+// after that many invalid records.
+//
+// Each failure is printed as "record N: attribute: message", or, with -report,
+// a summary of the failures of each attribute and rule is printed. The exit status
+// is 0 when every record is valid, 1 when a record is invalid and 2 on error.
+//
+// The lint subcommand checks a rule file instead, printing unknown rules,
+// malformed parameters, duplicates and rules that can never pass together:
+//
+//	validate lint rules.yaml
+//
+// Its exit status is 0 when no issue is found, 1 otherwise and 2 on error.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ragopkg/validation"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "lint" {
+		return lint(args[1:], stdout, stderr)
+	}
+
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	rulesPath := flags.String("rules", "", "rule definition file (.json, .yaml or .yml)")
+	format := flags.String("format", "", "input format: json, ndjson or csv (default: from the file extension)")
+	maxFailures := flags.Int("max-failures", 0, "stop after `n` invalid records (0: no limit)")
+	reportFormat := flags.String("report", "", "print a failure report as text, json or markdown instead of each failure")
+	columnsFlag := flags.String("columns", "", "CSV column types as `name=type,...` (string, integer, number or boolean)")
+	breachedPath := flags.String("breached", "", "breached passwords: a directory of hash prefix files or a file of SHA-1 hashes")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	writeReport, ok := reportWriters[*reportFormat]
+	columns, err := parseColumns(*columnsFlag)
+	if *rulesPath == "" || flags.NArg() > 1 || !ok || err != nil {
+		fmt.Fprintln(stderr, "usage: validate -rules rules.yaml [-format json|ndjson|csv] [-columns name=type,...] [-breached path] [-max-failures n] [-report text|json|markdown] [file]")
+		return 2
+	}
+
+	definition, err := validation.LoadFile(*rulesPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	var options []validation.Option
+	if *breachedPath != "" {
+		list, err := loadBreached(*breachedPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		options = append(options, validation.WithBreachedList(list))
+	}
+	ruleSet := definition.RuleSet(options...)
+
+	input := stdin
+	if flags.NArg() == 1 {
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		defer f.Close()
+		input = f
+
+		if *format == "" {
+			*format = formatOf(flags.Arg(0))
+		}
+	}
+	if *format == "" {
+		*format = validation.FormatJSON
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+
+	report := validation.NewReport(3)
+	opts := validation.StreamOptions{Format: *format, MaxFailures: *maxFailures, Columns: columns}
+	stats, err := ruleSet.Stream(input, opts, func(result validation.StreamResult) error {
+		if writeReport != nil {
+			report.Add(result.Data, result.Errors)
+			return nil
+		}
+		for _, e := range result.Errors {
+			fmt.Fprintf(out, "record %d: %s: %s\n", result.Record, e.Field, e.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		out.Flush()
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	if writeReport != nil {
+		if err := writeReport(report, out); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	}
+
+	if stats.Failed > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func lint(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "usage: validate lint rules.yaml")
+		return 2
+	}
+
+	// A definition with invalid rules is returned along with the error.
+	definition, err := validation.LoadFile(args[0])
+	if definition == nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	issues := validation.LintOrdered(definition.Rules)
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+	}
+
+	if len(issues) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+var reportWriters = map[string]func(*validation.Report, io.Writer) error{
+	"":         nil,
+	"text":     (*validation.Report).WriteText,
+	"json":     (*validation.Report).WriteJSON,
+	"markdown": (*validation.Report).WriteMarkdown,
+}
+
+// parseColumns parses the -columns flag, "name=type" pairs separated by commas.
+func parseColumns(s string) (map[string]string, error) {
+	columns := map[string]string{}
+	if s == "" {
+		return columns, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		name, typ, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid column type %q", pair)
+		}
+		columns[name] = typ
+	}
+
+	return columns, nil
+}
+
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return validation.FormatNDJSON
+	case ".csv":
+		return validation.FormatCSV
+	default:
+		return validation.FormatJSON
+	}
+}
+
+// loadBreached reads the breached password list of the -breached flag: a
+// directory of hash prefix files or a file of SHA-1 hashes.
+func loadBreached(path string) (validation.BreachedList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return validation.OpenPrefixDir(path)
+	}
+
+	return validation.LoadHashList(path)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(rules, []byte("rules:\n  name: required|alpha\n  email: required|email\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		format string
+		input  string
+		status int
+		output string
+	}{
+		"json-object": {"json", `{"name": "foo", "email": "foo@example.com"}`, 0, ""},
+		"json-array": {"json", `[{"name": "foo", "email": "foo@example.com"}, {"name": "f00", "email": "foo"}]`, 1,
+			"record 2: name: The name may only contain letters.\nrecord 2: email: The email must be a valid email address.\n"},
+		"ndjson": {"ndjson", "{\"name\": \"foo\", \"email\": \"foo@example.com\"}\n{\"email\": \"foo@example.com\"}\n", 1,
+			"record 2: name: The name field is required.\n"},
+		"csv": {"csv", "name,email\nfoo,foo@example.com\nfoo,\n", 1,
+			"record 2: email: The email field is required.\n"},
+		"invalid": {"json", `[1]`, 2, ""},
+	}
+
+	for name, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		status := run([]string{"-rules", rules, "-format", tt.format}, strings.NewReader(tt.input), &stdout, &stderr)
+		if status != tt.status {
+			t.Errorf("%s: expected status %d, got %d (%s)", name, tt.status, status, stderr.String())
+		}
+		if stdout.String() != tt.output {
+			t.Errorf("%s: unexpected output %q", name, stdout.String())
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"-rules", rules, "-format", "ndjson", "-report", "markdown"}, strings.NewReader(tests["ndjson"].input), &stdout, &stderr)
+	if status != 1 || !strings.Contains(stdout.String(), "| name | required | 1 | (missing) |") {
+		t.Errorf("unexpected report (%d): %s", status, stdout.String())
+	}
+}
+
+func TestRunCSVColumns(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(rules, []byte("rules:\n  name: required\n  email: email\n  age: integer|min:18\n  score: min:5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := "name,email,age,score\nfoo,,20,7.5\nbar,bar@example.com,17,\n"
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"-rules", rules, "-format", "csv", "-columns", "score=number"}, strings.NewReader(input), &stdout, &stderr)
+	if status != 1 || stdout.String() != "record 2: age: The age must be at least 18.\n" {
+		t.Errorf("unexpected result (%d): %q %s", status, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	status = run([]string{"-rules", rules, "-format", "csv", "-columns", "score"}, strings.NewReader(input), &stdout, &stderr)
+	if status != 2 {
+		t.Errorf("expected status 2 for an invalid -columns flag, got %d", status)
+	}
+}
+
+func TestRunBreached(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(rules, []byte("rules:\n  pw: password:uncompromised\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The SHA-1 hash of "password1".
+	hashes := filepath.Join(dir, "hashes.txt")
+	if err := os.WriteFile(hashes, []byte("E38AD214943DAAD1D64C102FAEC29DE4AFE9DA3D:2413945\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := "{\"pw\": \"password2\"}\n{\"pw\": \"password1\"}\n"
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"-rules", rules, "-format", "ndjson", "-breached", hashes}, strings.NewReader(input), &stdout, &stderr)
+	if status != 1 || !strings.HasPrefix(stdout.String(), "record 2: pw: The given pw has appeared in a data leak.") {
+		t.Errorf("unexpected result (%d): %q %s", status, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	status = run([]string{"-rules", rules, "-format", "ndjson", "-breached", dir}, strings.NewReader(input), &stdout, &stderr)
+	if status != 0 {
+		t.Errorf("expected an empty prefix directory to pass, got %d: %s", status, stderr.String())
+	}
+
+	status = run([]string{"-rules", rules, "-format", "ndjson"}, strings.NewReader(input), &stdout, &stderr)
+	if status != 2 {
+		t.Errorf("expected status 2 without -breached, got %d", status)
+	}
+}
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, "rules.json")
+	if err := os.WriteFile(rules, []byte(`{"rules": {"name": "required|string", "age": "min:10|max:5|foo"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"lint", rules}, nil, &stdout, &stderr)
+
+	expected := "age: foo: unknown rule\nage: rules min:10 and max:5 can never pass together\n"
+	if status != 1 || stdout.String() != expected {
+		t.Errorf("unexpected lint result (%d): %q %s", status, stdout.String(), stderr.String())
+	}
+}
+
+func TestRunEmptyInput(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(rules, []byte("rules:\n  name: required\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-rules", rules, "-format", "csv"}, strings.NewReader(""), &stdout, &stderr); status != 0 {
+		t.Errorf("expected status 0 for an empty input, got %d: %s", status, stderr.String())
+	}
+}
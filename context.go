@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"context"
+	"strings"
+)
+
+// Context is the context of a rule applied to an attribute.
+type Context struct {
+	// Context is the context.Context given by WithContext,
+	// context.Background() by default.
+	Context context.Context
+
+	// Attribute is the path of the attribute, such as "tags.0" for a rule
+	// declared for "tags.*".
+	Attribute  string
+	Value      interface{}
+	Parameters []string
+
+	// Data is the data being validated and Parent the map or slice holding
+	// the attribute, which is Data for a top-level attribute.
+	Data   map[string]interface{}
+	Parent interface{}
+
+	// Locale is the locale given by WithLocale.
+	Locale string
+
+	breached BreachedList
+}
+
+// Get returns the value of another attribute, nil if it is missing.
+func (c *Context) Get(attribute string) interface{} {
+	return getPath(c.Data, attribute)
+}
+
+// ContextRule is a rule with access to its Context. Validate returns nil when
+// the value is valid. A failure is reported with the message of the returned
+// error, or with the message and metadata of a *Failure.
+type ContextRule interface {
+	Validate(ctx *Context) error
+}
+
+// Failure is returned by a ContextRule to fail with a message, which may use
+// the :attribute placeholder, and metadata reported in FieldError.Meta.
+type Failure struct {
+	Message string
+	Meta    map[string]interface{}
+}
+
+func (f *Failure) Error() string {
+	return f.Message
+}
+
+func (v *validator) newContext(attribute string, value interface{}, parameters []string) *Context {
+	var parent interface{} = v.data
+	if i := strings.LastIndex(attribute, "."); i != -1 {
+		if _, ok := v.data[attribute]; !ok {
+			parent = getPath(v.data, attribute[:i])
+		}
+	}
+
+	return &Context{
+		Context:    v.ctx,
+		Attribute:  attribute,
+		Value:      value,
+		Parameters: parameters,
+		Data:       v.data,
+		Parent:     parent,
+		Locale:     v.locale,
+		breached:   v.breached,
+	}
+}
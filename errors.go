@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrRuleNotSupported is returned for a rule name that is not registered.
+	ErrRuleNotSupported = errors.New("validation: rule not supported")
+
+	// ErrInvalidRule is returned for rules that are empty or of a wrong type.
+	ErrInvalidRule = errors.New("validation: invalid rule")
+
+	// ErrInvalidParameter is returned for missing or malformed rule parameters.
+	ErrInvalidParameter = errors.New("validation: invalid parameter")
+)
+
+// FieldError describes a rule that failed for an attribute. It marshals to
+// JSON as {"field": ..., "rule": ..., "params": [...], "message": ...}.
+type FieldError struct {
+	Field      string   `json:"field"`
+	Rule       string   `json:"rule"`
+	Parameters []string `json:"params,omitempty"`
+	Message    string   `json:"message"`
+
+	// Pattern is the attribute as declared in the rules, such as "items.*.id"
+	// for the field "items.0.id".
+	Pattern string `json:"-"`
+
+	// Sensitive reports whether the attribute has a rule of secret values,
+	// such as password, whose values are not sampled by a Report.
+	Sensitive bool `json:"-"`
+
+	// Meta is the metadata of the failure of a ContextRule.
+	Meta map[string]interface{} `json:"meta,omitempty"`
+}
+
+// ValidationErrors is the list of failures of a validation run.
+type ValidationErrors []*FieldError
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+
+	return strings.Join(messages, " ")
+}
+
+// Unwrap returns one error per field failure, so that errors.As can extract a
+// *FieldError.
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// isConfigError reports whether err is caused by the rule definitions rather
+// than by the data being validated.
+func isConfigError(err error) bool {
+	return errors.Is(err, ErrRuleNotSupported) || errors.Is(err, ErrInvalidRule) || errors.Is(err, ErrInvalidParameter)
+}
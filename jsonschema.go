@@ -0,0 +1,466 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedKeyword is returned by FromJSONSchema for schema keywords or
+// keyword values that have no equivalent rule.
+var ErrUnsupportedKeyword = errors.New("validation: unsupported JSON Schema keyword")
+
+// jsonSchemaAnnotations are keywords that do not constrain the data.
+var jsonSchemaAnnotations = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+	"deprecated":  true,
+	"readOnly":    true,
+	"writeOnly":   true,
+}
+
+var jsonSchemaTypes = map[string]string{
+	"string":  "string",
+	"number":  "float",
+	"integer": "integer",
+	"boolean": "bool",
+	"object":  "",
+	"array":   "",
+}
+
+var jsonSchemaFormats = map[string]string{
+	"email": "email",
+}
+
+// FromJSONSchema converts a JSON Schema object (a subset of draft 2020-12:
+// type, required, minLength, maxLength, minimum, maximum, enum, pattern,
+// format, items and properties) into an ordered rule list. Nested properties
+// become dotted attributes and array items become "attribute.*". A required
+// property of an optional object becomes required_with the object.
+//
+// Keywords that cannot be converted are reported together in an error
+// wrapping ErrUnsupportedKeyword; the rules converted from the other keywords
+// are returned along with it.
+func FromJSONSchema(schema []byte) ([]AttributeRules, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("validation: invalid JSON Schema: %w", err)
+	}
+
+	c := &schemaConverter{}
+	for keyword, value := range root {
+		switch keyword {
+		case "type":
+			if value != "object" {
+				c.unsupportedKeyword("", keyword)
+			}
+		case "properties", "required":
+		default:
+			if !jsonSchemaAnnotations[keyword] {
+				c.unsupportedKeyword("", keyword)
+			}
+		}
+	}
+	c.convertProperties(root, "", "", true)
+	for _, attributeRules := range c.rules {
+		if _, err := toRules(attributeRules.Attribute, attributeRules.Rules); err != nil {
+			return nil, fmt.Errorf("validation: invalid rules converted from JSON Schema: %w", err)
+		}
+	}
+	sort.Strings(c.unsupported)
+	if len(c.unsupported) > 0 {
+		return c.rules, fmt.Errorf("%w: %s", ErrUnsupportedKeyword, strings.Join(c.unsupported, ", "))
+	}
+
+	return c.rules, nil
+}
+
+type schemaConverter struct {
+	rules       []AttributeRules
+	unsupported []string
+}
+
+func (c *schemaConverter) unsupportedKeyword(pointer, keyword string) {
+	c.unsupported = append(c.unsupported, pointer+"/"+keyword)
+}
+
+// convertProperties converts the properties of an object schema. present
+// reports whether the object exists whenever its properties are validated:
+// the root, a required object of a present object, or an array item. The
+// required properties of an object that may be missing are only required
+// with the object.
+func (c *schemaConverter) convertProperties(schema map[string]interface{}, attribute, pointer string, present bool) {
+	required := map[string]bool{}
+	if value, ok := schema["required"]; ok {
+		names, _ := value.([]interface{})
+		for _, name := range names {
+			s, ok := name.(string)
+			if !ok {
+				c.unsupportedKeyword(pointer, "required")
+				break
+			}
+			required[s] = true
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child, ok := properties[name].(map[string]interface{})
+		if !ok {
+			c.unsupportedKeyword(pointer+"/properties", name)
+			continue
+		}
+		requiredRule := ""
+		if required[name] && present {
+			requiredRule = "required"
+		} else if required[name] {
+			requiredRule = "required_with:" + escapeParameter(attribute)
+		}
+		c.convert(child, joinAttribute(attribute, name), pointer+"/properties/"+name, requiredRule)
+	}
+}
+
+// convert appends the rules of the attribute described by schema, starting
+// with requiredRule when it is not empty, then the rules of its properties
+// and items.
+func (c *schemaConverter) convert(schema map[string]interface{}, attribute, pointer, requiredRule string) {
+	rules := []string{}
+	if requiredRule != "" {
+		rules = append(rules, requiredRule)
+	}
+
+	typ, _ := schema["type"].(string)
+	if rule, ok := jsonSchemaTypes[typ]; ok && rule != "" {
+		rules = append(rules, rule)
+	}
+
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		value := schema[keyword]
+		switch keyword {
+		case "type":
+			if _, ok := jsonSchemaTypes[typ]; !ok {
+				c.unsupportedKeyword(pointer, keyword)
+			}
+		case "minLength", "minimum":
+			if n, ok := value.(float64); ok {
+				rules = append(rules, "min:"+strconv.FormatFloat(n, 'f', -1, 64))
+			} else {
+				c.unsupportedKeyword(pointer, keyword)
+			}
+		case "maxLength", "maximum":
+			if n, ok := value.(float64); ok {
+				rules = append(rules, "max:"+strconv.FormatFloat(n, 'f', -1, 64))
+			} else {
+				c.unsupportedKeyword(pointer, keyword)
+			}
+		case "enum":
+			values, ok := value.([]interface{})
+			if _, scalars := toStringSlice(values); !ok || !scalars || len(values) == 0 {
+				c.unsupportedKeyword(pointer, keyword)
+			} else {
+				rules = append(rules, In(values))
+			}
+		case "pattern":
+			if pattern, ok := value.(string); ok {
+				rules = append(rules, "regex:"+quoteParameter(pattern))
+			} else {
+				c.unsupportedKeyword(pointer, keyword)
+			}
+		case "format":
+			format, _ := value.(string)
+			if rule, ok := jsonSchemaFormats[format]; ok {
+				rules = append(rules, rule)
+			} else {
+				c.unsupportedKeyword(pointer, keyword)
+			}
+		case "properties", "required":
+			if typ != "object" {
+				c.unsupportedKeyword(pointer, keyword)
+			}
+		case "items":
+			if _, ok := value.(map[string]interface{}); !ok || typ != "array" {
+				c.unsupportedKeyword(pointer, keyword)
+			}
+		default:
+			if !jsonSchemaAnnotations[keyword] {
+				c.unsupportedKeyword(pointer, keyword)
+			}
+		}
+	}
+
+	if len(rules) > 0 {
+		c.rules = append(c.rules, AttributeRules{attribute, rules})
+	}
+
+	switch typ {
+	case "object":
+		c.convertProperties(schema, attribute, pointer, requiredRule == "required" || strings.HasSuffix(attribute, "*"))
+	case "array":
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			c.convert(items, attribute+".*", pointer+"/items", "")
+		}
+	}
+}
+
+func joinAttribute(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+// ToJSONSchema returns the JSON Schema (draft 2020-12) object equivalent to
+// rules, the rules map passed to New. Dotted attributes become nested
+// properties and "attribute.*" becomes the items of an array.
+//
+// Like the rules, min, max, size and between constrain the length of strings
+// and the value of numbers; both keywords are emitted when the type of the
+// attribute is not known. Rules without a schema equivalent, such as in_array
+// or Rules implemented in Go, are left out. Note that patterns are written in
+// Go regexp syntax.
+func ToJSONSchema(rules map[string]interface{}) (map[string]interface{}, error) {
+	schema, err := ToOpenAPISchema(rules)
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+
+	return schema, nil
+}
+
+// ToOpenAPISchema returns the OpenAPI 3.1 schema object equivalent to rules.
+// It is the schema of ToJSONSchema without the $schema keyword.
+func ToOpenAPISchema(rules map[string]interface{}) (map[string]interface{}, error) {
+	attributes := make([]string, 0, len(rules))
+	for attribute := range rules {
+		attributes = append(attributes, attribute)
+	}
+	sort.Strings(attributes)
+
+	root := map[string]interface{}{"type": "object"}
+	for _, attribute := range attributes {
+		r, err := toRules(attribute, rules[attribute])
+		if err != nil {
+			return nil, err
+		}
+
+		segments := strings.Split(attribute, ".")
+		required, err := addRuleKeywords(schemaNode(root, segments), r)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			markRequired(root, segments)
+		}
+	}
+
+	return root, nil
+}
+
+// schemaNode returns the schema of an attribute path, creating the schemas
+// on the way.
+func schemaNode(root map[string]interface{}, segments []string) map[string]interface{} {
+	node := root
+	for _, segment := range segments {
+		var child map[string]interface{}
+		if segment == "*" {
+			node["type"] = "array"
+			if child, _ = node["items"].(map[string]interface{}); child == nil {
+				child = map[string]interface{}{}
+				node["items"] = child
+			}
+		} else {
+			node["type"] = "object"
+			properties, _ := node["properties"].(map[string]interface{})
+			if properties == nil {
+				properties = map[string]interface{}{}
+				node["properties"] = properties
+			}
+			if child, _ = properties[segment].(map[string]interface{}); child == nil {
+				child = map[string]interface{}{}
+				properties[segment] = child
+			}
+		}
+		node = child
+	}
+
+	return node
+}
+
+// markRequired adds an attribute path to the required properties. As the
+// value of a required nested attribute must exist, its parents are required
+// as well, except arrays: a wildcard matching no element has nothing to
+// validate, so "tags.*" does not require tags, only the properties of the
+// items below it.
+func markRequired(root map[string]interface{}, segments []string) {
+	node := root
+	for i, segment := range segments {
+		if segment == "*" {
+			node = node["items"].(map[string]interface{})
+			continue
+		}
+		if i+1 < len(segments) && segments[i+1] == "*" {
+			node = node["properties"].(map[string]interface{})[segment].(map[string]interface{})
+			continue
+		}
+
+		names, _ := node["required"].([]string)
+		found := false
+		for _, name := range names {
+			found = found || name == segment
+		}
+		if !found {
+			node["required"] = append(names, segment)
+		}
+
+		node = node["properties"].(map[string]interface{})[segment].(map[string]interface{})
+	}
+}
+
+// ruleSchemaTypes are the schema types implied by rules.
+var ruleSchemaTypes = map[string]string{
+	"alpha":     "string",
+	"alpha_num": "string",
+	"bool":      "boolean",
+	"email":     "string",
+	"float":     "number",
+	"integer":   "integer",
+	"num":       "string",
+	"regex":     "string",
+	"string":    "string",
+}
+
+// rulePatterns are the patterns of the rules implemented by a regexp.
+var rulePatterns = map[string]string{
+	"alpha":     REGEXP_ALPHA,
+	"alpha_num": REGEXP_ALPHA_NUM,
+	"num":       REGEXP_NUM,
+}
+
+// addRuleKeywords adds the keywords of rules to node and reports whether the
+// attribute is required.
+func addRuleKeywords(node map[string]interface{}, rules []rule) (bool, error) {
+	typ := ""
+	for _, rule := range rules {
+		if t, ok := ruleSchemaTypes[rule.name]; ok && typ == "" {
+			typ = t
+		}
+	}
+	if typ != "" {
+		node["type"] = typ
+	}
+
+	// setBound sets the string length or number bound, or both.
+	setBound := func(lengthKeyword, numberKeyword, parameter string) error {
+		n, err := strconv.ParseFloat(parameter, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid number %q", ErrInvalidParameter, parameter)
+		}
+		if typ == "" || typ == "string" {
+			node[lengthKeyword] = n
+		}
+		if typ != "string" {
+			node[numberKeyword] = n
+		}
+		return nil
+	}
+
+	required := false
+	for _, rule := range rules {
+		if rule.custom != nil {
+			continue
+		}
+
+		name, parameters := rule.name, rule.parameters
+		if _, err := getRuleMethod(name); err != nil {
+			return false, err
+		}
+		if count := ruleParameterCounts[name]; len(parameters) < count {
+			return false, fmt.Errorf("%w: rule %s requires at least %d parameters", ErrInvalidParameter, name, count)
+		}
+
+		var err error
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			node["format"] = "email"
+		case "alpha", "alpha_num", "num":
+			addPattern(node, rulePatterns[name])
+		case "regex":
+			addPattern(node, parameters[0])
+		case "min":
+			err = setBound("minLength", "minimum", parameters[0])
+		case "max":
+			err = setBound("maxLength", "maximum", parameters[0])
+		case "size":
+			if err = setBound("minLength", "minimum", parameters[0]); err == nil {
+				err = setBound("maxLength", "maximum", parameters[0])
+			}
+		case "between":
+			if err = setBound("minLength", "minimum", parameters[0]); err == nil {
+				err = setBound("maxLength", "maximum", parameters[1])
+			}
+		case "in":
+			node["enum"] = enumValues(typ, parameters)
+		case "not_in":
+			node["not"] = map[string]interface{}{"enum": enumValues(typ, parameters)}
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return required, nil
+}
+
+// addPattern sets the pattern of node, moving additional patterns to allOf
+// since a schema holds a single pattern.
+func addPattern(node map[string]interface{}, pattern string) {
+	if _, ok := node["pattern"]; !ok {
+		node["pattern"] = pattern
+		return
+	}
+
+	allOf, _ := node["allOf"].([]interface{})
+	node["allOf"] = append(allOf, map[string]interface{}{"pattern": pattern})
+}
+
+// enumValues returns the values of an in rule. Like the rule, which compares
+// the string form of numbers, numeric parameters match both numbers and
+// strings unless the type of the attribute is known.
+func enumValues(typ string, parameters []string) []interface{} {
+	values := []interface{}{}
+	for _, parameter := range parameters {
+		n, err := strconv.ParseFloat(parameter, 64)
+		if typ != "string" && err == nil {
+			values = append(values, n)
+		}
+		if typ == "" || typ == "string" || err != nil {
+			values = append(values, parameter)
+		}
+	}
+
+	return values
+}
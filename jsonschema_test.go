@@ -0,0 +1,232 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromJSONSchema(t *testing.T) {
+	rules, err := FromJSONSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"required": ["name", "email"],
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "maxLength": 20, "pattern": "^[A-Z]"},
+			"email": {"type": "string", "format": "email"},
+			"age": {"type": "integer", "minimum": 18},
+			"role": {"enum": ["admin", "user, guest"]},
+			"address": {
+				"type": "object",
+				"required": ["city"],
+				"properties": {"city": {"type": "string"}}
+			},
+			"tags": {"type": "array", "items": {"type": "string", "maxLength": 5}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []AttributeRules{
+		{"address.city", []string{"required_with:address", "string"}},
+		{"age", []string{"integer", "min:18"}},
+		{"email", []string{"required", "string", "email"}},
+		{"name", []string{"required", "string", "max:20", "min:2", `regex:"^[A-Z]"`}},
+		{"role", []string{`in:admin,user\, guest`}},
+		{"tags.*", []string{"string", "max:5"}},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("unexpected rules %v", rules)
+	}
+
+	data := map[string]interface{}{
+		"name":    "Foo",
+		"email":   "foo@example.com",
+		"age":     18.0,
+		"role":    "user, guest",
+		"address": map[string]interface{}{"city": "Paris"},
+		"tags":    []interface{}{"a", "b"},
+	}
+	if err := NewOrdered(data, rules).Validate(); err != nil {
+		t.Errorf("expected data to be valid, got %v", err)
+	}
+
+	data["tags"] = []interface{}{"a", "abcdef"}
+	errs := NewOrdered(data, rules).Errors()
+	if len(errs) != 1 || errs[0].Field != "tags.1" {
+		t.Errorf("expected an error for tags.1, got %v", errs)
+	}
+
+	// address is optional, its city is only required when it is present.
+	delete(data, "address")
+	data["tags"] = []interface{}{"a"}
+	if err := NewOrdered(data, rules).Validate(); err != nil {
+		t.Errorf("expected data without address to be valid, got %v", err)
+	}
+	data["address"] = map[string]interface{}{"zip": "75001"}
+	errs = NewOrdered(data, rules).Errors()
+	if len(errs) != 1 || errs[0].Message != "The address.city field is required when address is present." {
+		t.Errorf("expected an error for address.city, got %v", errs)
+	}
+}
+
+func TestFromJSONSchemaStrings(t *testing.T) {
+	rules, err := FromJSONSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"quoted": {"type": "string", "pattern": "^(\"a\"|\"b\")$"},
+			"spaced": {"type": "string", "pattern": " x\\d "},
+			"word": {"type": "string", "maxLength": 5}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := map[string]interface{}{"quoted": `"a"`, "spaced": "a x1 b", "word": "héllo"}
+	if err := NewOrdered(valid, rules).Validate(); err != nil {
+		t.Errorf("expected data to be valid, got %v", err)
+	}
+	invalid := map[string]interface{}{"quoted": "a", "spaced": "x1", "word": "héllos"}
+	if errs := NewOrdered(invalid, rules).Errors(); len(errs) != 3 {
+		t.Errorf("expected 3 errors, got %v", errs)
+	}
+}
+
+func TestFromJSONSchemaNestedRequired(t *testing.T) {
+	rules, err := FromJSONSchema([]byte(`{
+		"type": "object",
+		"required": ["user"],
+		"properties": {
+			"user": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {"name": {"type": "string"}}
+			},
+			"items": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"required": ["id"],
+					"properties": {
+						"id": {"type": "integer"},
+						"address": {
+							"type": "object",
+							"required": ["city"],
+							"properties": {"city": {"type": "string"}}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []AttributeRules{
+		{"items.*.address.city", []string{"required_with:items.*.address", "string"}},
+		{"items.*.id", []string{"required", "integer"}},
+		{"user", []string{"required"}},
+		{"user.name", []string{"required", "string"}},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("unexpected rules %v", rules)
+	}
+
+	data := map[string]interface{}{
+		"user": map[string]interface{}{"name": "Foo"},
+		"items": []interface{}{
+			map[string]interface{}{"id": 1},
+			map[string]interface{}{"id": 2, "address": map[string]interface{}{"zip": "75001"}},
+		},
+	}
+	errs := NewOrdered(data, rules).Errors()
+	if len(errs) != 1 || errs[0].Field != "items.1.address.city" {
+		t.Errorf("expected an error for items.1.address.city, got %v", errs)
+	}
+}
+
+func TestFromJSONSchemaUnsupported(t *testing.T) {
+	rules, err := FromJSONSchema([]byte(`{
+		"type": "object",
+		"minProperties": 1,
+		"properties": {
+			"name": {"type": "string", "format": "uri"},
+			"age": {"type": "integer", "multipleOf": 2},
+			"any": {"oneOf": [{"type": "string"}]}
+		}
+	}`))
+	if !errors.Is(err, ErrUnsupportedKeyword) {
+		t.Fatalf("expected ErrUnsupportedKeyword, got %v", err)
+	}
+	for _, pointer := range []string{"/minProperties", "/properties/name/format", "/properties/age/multipleOf", "/properties/any/oneOf"} {
+		if !strings.Contains(err.Error(), pointer) {
+			t.Errorf("expected %s to be reported, got %v", pointer, err)
+		}
+	}
+
+	if len(rules) != 2 {
+		t.Errorf("expected the supported rules to be returned, got %v", rules)
+	}
+}
+
+func TestToJSONSchema(t *testing.T) {
+	schema, err := ToJSONSchema(map[string]interface{}{
+		"name":         "required|string|between:2,20",
+		"age":          "integer|min:18",
+		"email":        "required|email",
+		"role":         "in:admin,user",
+		"level":        "in:1,2",
+		"code":         "alpha|size:3",
+		"tags.*":       "required|string|max:5",
+		"address.city": "required|string",
+		"items.*.id":   "required|integer",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"$schema":  "https://json-schema.org/draft/2020-12/schema",
+		"type":     "object",
+		"required": []string{"address", "email", "name"},
+		"properties": map[string]interface{}{
+			"address": map[string]interface{}{
+				"type":       "object",
+				"required":   []string{"city"},
+				"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+			},
+			"age":   map[string]interface{}{"type": "integer", "minimum": 18.0},
+			"code":  map[string]interface{}{"type": "string", "pattern": REGEXP_ALPHA, "minLength": 3.0, "maxLength": 3.0},
+			"email": map[string]interface{}{"type": "string", "format": "email"},
+			"level": map[string]interface{}{"enum": []interface{}{1.0, "1", 2.0, "2"}},
+			"name":  map[string]interface{}{"type": "string", "minLength": 2.0, "maxLength": 20.0},
+			"role":  map[string]interface{}{"enum": []interface{}{"admin", "user"}},
+			"tags": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string", "maxLength": 5.0},
+			},
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":       "object",
+					"required":   []string{"id"},
+					"properties": map[string]interface{}{"id": map[string]interface{}{"type": "integer"}},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("unexpected schema %v", schema)
+	}
+
+	if _, err := ToOpenAPISchema(map[string]interface{}{"foo": "unknown"}); !errors.Is(err, ErrRuleNotSupported) {
+		t.Errorf("expected ErrRuleNotSupported, got %v", err)
+	}
+	if _, err := ToOpenAPISchema(map[string]interface{}{"foo": "max:a"}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LintIssue is a problem found by Lint in the rules of an attribute.
+type LintIssue struct {
+	Attribute string `json:"attribute"`
+	Rule      string `json:"rule,omitempty"`
+	Message   string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	if i.Rule == "" {
+		return i.Attribute + ": " + i.Message
+	}
+
+	return i.Attribute + ": " + i.Rule + ": " + i.Message
+}
+
+// Lint reports the problems of rules, the rules map passed to New: rules that
+// cannot be parsed, unknown rules, malformed parameters (including the
+// parameters of rules such as password, phone or country), duplicate rules, and
+// combinations of rules that can never pass together, such as
+// "min:10|max:5", "size:3|between:5,9" or "bool|email".
+func Lint(rules map[string]interface{}) []LintIssue {
+	attributes := make([]string, 0, len(rules))
+	for attribute := range rules {
+		attributes = append(attributes, attribute)
+	}
+	sort.Strings(attributes)
+
+	ordered := make([]AttributeRules, len(attributes))
+	for i, attribute := range attributes {
+		ordered[i] = AttributeRules{attribute, rules[attribute]}
+	}
+
+	return LintOrdered(ordered)
+}
+
+// LintOrdered is Lint for an ordered rule list.
+func LintOrdered(rules []AttributeRules) []LintIssue {
+	issues := []LintIssue{}
+	seen := map[string]bool{}
+
+	for _, attributeRules := range rules {
+		attribute := attributeRules.Attribute
+		if seen[attribute] {
+			issues = append(issues, LintIssue{attribute, "", "duplicate rules for the attribute"})
+			continue
+		}
+		seen[attribute] = true
+
+		issues = append(issues, lintAttribute(attribute, attributeRules.Rules)...)
+	}
+
+	return issues
+}
+
+// lintBounds are the bounds on the size of a value implied by min, max, size
+// and between.
+type lintBounds struct {
+	min, max         float64
+	minRule, maxRule string
+}
+
+func lintAttribute(attribute string, rule interface{}) []LintIssue {
+	issues := []LintIssue{}
+	add := func(rule, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{attribute, rule, fmt.Sprintf(format, args...)})
+	}
+
+	rules, err := toRules(attribute, rule)
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if errors.As(err, &syntaxErr) {
+			add("", "syntax error in %q at offset %d: %s", syntaxErr.Rule, syntaxErr.Offset, syntaxErr.Msg)
+		} else {
+			add("", "invalid rules, a non-empty rule string, rule list or Rule expected")
+		}
+		return issues
+	}
+
+	seen := map[string]string{}
+	types := map[string]string{}
+	bounds := lintBounds{min: math.Inf(-1), max: math.Inf(1)}
+	sizeRules := []string{}
+
+	for _, rule := range rules {
+		if rule.custom != nil {
+			continue
+		}
+
+		r, name, parameters := rule.raw, rule.name, rule.parameters
+		if _, err := getRuleMethod(name); err != nil {
+			add(r, "unknown rule")
+			continue
+		}
+
+		if previous, ok := seen[name]; ok {
+			if previous == r {
+				add(r, "duplicate rule")
+			} else if name != "regex" {
+				add(r, "rule %s is already given as %q", name, previous)
+			}
+		} else {
+			seen[name] = r
+		}
+
+		if count := ruleParameterCounts[name]; len(parameters) < count {
+			add(r, "requires at least %d parameters", count)
+			continue
+		}
+
+		if typ, ok := ruleSchemaTypes[name]; ok {
+			types[typ] = r
+		}
+
+		if _, ok := defaultRuleMessages2[name]; ok {
+			sizeRules = append(sizeRules, r)
+			if !lintSizeRule(name, parameters, r, &bounds, add) {
+				continue
+			}
+		}
+
+		if name == "regex" {
+			if _, err := regexp.Compile(parameters[0]); err != nil {
+				add(r, "invalid pattern: %v", err)
+			}
+		}
+
+		if check, ok := ruleParameterCheckers[name]; ok {
+			if err := check(parameters); err != nil {
+				add(r, "%s", strings.TrimPrefix(err.Error(), ErrInvalidParameter.Error()+": "))
+			}
+		} else if _, ok := ruleParameterCounts[name]; !ok && len(parameters) > 0 {
+			add(r, "rule %s takes no parameters", name)
+		}
+	}
+
+	// Rules implying a different type of value can never pass together,
+	// except integer and float which both accept integral float64 values.
+	if len(types) > 1 {
+		typeRules := []string{}
+		for typ, r := range types {
+			if typ != "number" || types["integer"] == "" {
+				typeRules = append(typeRules, r)
+			}
+		}
+		if len(typeRules) > 1 {
+			sort.Strings(typeRules)
+			add("", "rules %s can never pass together, they require different types", strings.Join(typeRules, ", "))
+		}
+	}
+
+	if r, ok := types["boolean"]; ok && len(sizeRules) > 0 {
+		add("", "rules %s can never pass with %s, a boolean has no size", strings.Join(sizeRules, ", "), r)
+	}
+
+	if bounds.min > bounds.max {
+		if bounds.minRule == bounds.maxRule {
+			add(bounds.minRule, "the minimum is greater than the maximum")
+		} else {
+			add("", "rules %s and %s can never pass together", bounds.minRule, bounds.maxRule)
+		}
+	}
+
+	return issues
+}
+
+// lintSizeRule checks the numeric parameters of min, max, size and between
+// and narrows bounds. It returns false when a parameter is not a number.
+func lintSizeRule(name string, parameters []string, r string, bounds *lintBounds, add func(string, string, ...interface{})) bool {
+	numbers := make([]float64, len(parameters))
+	for i, parameter := range parameters {
+		n, err := strconv.ParseFloat(parameter, 64)
+		if err != nil {
+			add(r, "parameter %q is not a number", parameter)
+			return false
+		}
+		numbers[i] = n
+	}
+
+	lower, upper := numbers[0], numbers[0]
+	switch name {
+	case "min":
+		upper = math.Inf(1)
+	case "max":
+		lower = math.Inf(-1)
+	case "between":
+		upper = numbers[1]
+	}
+
+	if lower > bounds.min {
+		bounds.min, bounds.minRule = lower, r
+	}
+	if upper < bounds.max {
+		bounds.max, bounds.maxRule = upper, r
+	}
+	if lower > upper {
+		bounds.minRule, bounds.maxRule = r, r
+	}
+
+	return true
+}
@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := map[string]struct {
+		rules  interface{}
+		issues []string
+	}{
+		"valid":          {"required|string|between:1,5", nil},
+		"float-integer":  {"float|integer|min:1", nil},
+		"min-max":        {"min:10|max:5", []string{"foo: rules min:10 and max:5 can never pass together"}},
+		"size-between":   {"size:3|between:5,9", []string{"foo: rules between:5,9 and size:3 can never pass together"}},
+		"between":        {"between:9,5", []string{"foo: between:9,5: the minimum is greater than the maximum"}},
+		"bool-email":     {"bool|email", []string{"foo: rules bool, email can never pass together, they require different types"}},
+		"string-float":   {"string|float", []string{"foo: rules float, string can never pass together, they require different types"}},
+		"bool-max":       {"bool|max:3", []string{"foo: rules max:3 can never pass with bool, a boolean has no size"}},
+		"duplicate":      {"required|required", []string{"foo: required: duplicate rule"}},
+		"duplicate-max":  {"max:3|max:4", []string{"foo: max:4: rule max is already given as \"max:3\""}},
+		"unknown":        {"required|foo", []string{"foo: foo: unknown rule"}},
+		"parameters":     {"between:1", []string{"foo: between:1: requires at least 2 parameters"}},
+		"not-a-number":   {"max:a", []string{"foo: max:a: parameter \"a\" is not a number"}},
+		"invalid-regex":  {[]string{"regex:a("}, []string{"foo: regex:a(: invalid pattern: error parsing regexp: missing closing ): `a(`"}},
+		"syntax":         {`in:"a`, []string{"foo: syntax error in \"in:\\\"a\" at offset 3: unterminated quoted parameter"}},
+		"invalid-type":   {1, []string{"foo: invalid rules, a non-empty rule string, rule list or Rule expected"}},
+		"multiple-regex": {[]string{"regex:^a", "regex:b$"}, nil},
+		"password":       {"password:foo", []string{"foo: password:foo: rule password has an unknown requirement \"foo\""}},
+		"password-min":   {"password:min=x,uncompromised", []string{"foo: password:min=x,uncompromised: rule password requires a non-negative integer min, got \"x\""}},
+		"phone":          {"phone:XX", []string{"foo: phone:XX: rule phone has an unknown region \"XX\""}},
+		"phone-valid":    {"phone:e164,US", nil},
+		"timezone":       {"timezone:foo", []string{"foo: timezone:foo: rule timezone takes no parameters"}},
+		"currency":       {"currency:bar", []string{"foo: currency:bar: rule currency takes no parameters"}},
+		"latitude":       {"latitude:1", []string{"foo: latitude:1: rule latitude takes no parameters"}},
+		"within_bbox":    {"within_bbox:1,2,x,4", []string{"foo: within_bbox:1,2,x,4: rule within_bbox requires a float string, got \"x\""}},
+		"isbn":           {"isbn:12", []string{"foo: isbn:12: rule isbn requires 10 or 13, got \"12\""}},
+		"ean":            {"ean:10", []string{"foo: ean:10: rule ean requires 8 or 13, got \"10\""}},
+		"country":        {"country:numeric", []string{"foo: country:numeric: rule country requires alpha2 or alpha3, got \"numeric\""}},
+		"credit_card":    {"credit_card:foo", []string{"foo: credit_card:foo: rule credit_card has an unknown brand \"foo\""}},
+		"geojson":        {"geojson:Line", []string{"foo: geojson:Line: rule geojson requires Point or Polygon, got \"Line\""}},
+	}
+
+	for name, tt := range tests {
+		issues := Lint(map[string]interface{}{"foo": tt.rules})
+		if len(issues) != len(tt.issues) {
+			t.Errorf("%s: expected %v, got %v", name, tt.issues, issues)
+			continue
+		}
+		for i, issue := range issues {
+			if issue.String() != tt.issues[i] {
+				t.Errorf("%s: expected %q, got %q", name, tt.issues[i], issue.String())
+			}
+		}
+	}
+
+	issues := LintOrdered([]AttributeRules{{"foo", "string"}, {"foo", "string"}})
+	if len(issues) != 1 || issues[0].String() != "foo: duplicate rules for the attribute" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
@@ -0,0 +1,206 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is a rule set loaded from a JSON or YAML document such as
+//
+//	{
+//	  "rules": {"name": "required|string|max:20", "age": ["required", "min:18"]},
+//	  "messages": {"age.min": "You must be an adult."},
+//	  "attributes": {"name": "full name"}
+//	}
+//
+// Rules keep the order in which they appear in the document.
+type Definition struct {
+	Rules      []AttributeRules
+	Messages   map[string]string
+	Attributes map[string]string
+}
+
+// LoadJSON reads a Definition from a JSON document. When the document is
+// well-formed but its rules are not, the Definition is returned along with
+// the error so that it can still be passed to LintOrdered.
+func LoadJSON(r io.Reader) (*Definition, error) {
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("validation: invalid rule definition: %w", err)
+	}
+
+	d := &Definition{}
+	for key, raw := range doc {
+		var err error
+		switch key {
+		case "rules":
+			d.Rules, err = decodeJSONRules(raw)
+		case "messages":
+			err = json.Unmarshal(raw, &d.Messages)
+		case "attributes":
+			err = json.Unmarshal(raw, &d.Attributes)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("validation: invalid rule definition: %w", err)
+		}
+	}
+
+	return d, d.check()
+}
+
+// decodeJSONRules decodes the "rules" object token by token to keep the order
+// of the attributes.
+func decodeJSONRules(raw json.RawMessage) ([]AttributeRules, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("rules must be an object")
+	}
+
+	rules := []AttributeRules{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		attribute, _ := t.(string)
+
+		var rule interface{}
+		if err := dec.Decode(&rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, AttributeRules{attribute, stringList(rule)})
+	}
+
+	return rules, nil
+}
+
+// LoadYAML reads a Definition from a YAML document with the same layout as
+// the JSON one.
+func LoadYAML(r io.Reader) (*Definition, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("validation: invalid rule definition: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("validation: invalid rule definition: a mapping is expected")
+	}
+
+	d := &Definition{}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, node := root.Content[i].Value, root.Content[i+1]
+
+		var err error
+		switch key {
+		case "rules":
+			d.Rules, err = decodeYAMLRules(node)
+		case "messages":
+			err = node.Decode(&d.Messages)
+		case "attributes":
+			err = node.Decode(&d.Attributes)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("validation: invalid rule definition: %w", err)
+		}
+	}
+
+	return d, d.check()
+}
+
+func decodeYAMLRules(node *yaml.Node) ([]AttributeRules, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("rules must be a mapping")
+	}
+
+	rules := []AttributeRules{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var rule interface{}
+		if err := node.Content[i+1].Decode(&rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, AttributeRules{node.Content[i].Value, stringList(rule)})
+	}
+
+	return rules, nil
+}
+
+// LoadFile reads a Definition from a .json, .yaml or .yml file.
+func LoadFile(path string) (*Definition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return LoadJSON(f)
+	case ".yaml", ".yml":
+		return LoadYAML(f)
+	default:
+		return nil, fmt.Errorf("validation: unsupported rule definition file %s", path)
+	}
+}
+
+// RuleSet returns the rule set of the definition, with its messages and
+// attribute names applied before opts.
+func (d *Definition) RuleSet(opts ...Option) *RuleSet {
+	opts = append([]Option{WithMessages(d.Messages), WithAttributeNames(d.Attributes)}, opts...)
+
+	return NewOrderedRuleSet(d.Rules, opts...)
+}
+
+// check performs the checks of NewOrderedRuleSet, and verifies that every
+// rule is supported, returning an error instead of panicking.
+func (d *Definition) check() error {
+	seen := map[string]bool{}
+	for _, attributeRules := range d.Rules {
+		if seen[attributeRules.Attribute] {
+			return fmt.Errorf("%w: duplicate rules for attribute %s", ErrInvalidRule, attributeRules.Attribute)
+		}
+		seen[attributeRules.Attribute] = true
+
+		rules, err := toRules(attributeRules.Attribute, attributeRules.Rules)
+		if err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			if _, err := getRuleMethod(rule.name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// stringList converts a decoded list of strings to a []string so that it is
+// accepted as a rule list; any other value is returned unchanged.
+func stringList(value interface{}) interface{} {
+	list, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+
+	strList := make([]string, len(list))
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return value
+		}
+		strList[i] = s
+	}
+
+	return strList
+}
@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	docs := map[string]func(string) (*Definition, error){
+		`{
+			"rules": {"name": "required|string|max:3", "age": ["required", "min:18"]},
+			"messages": {"age.min": "You must be an adult."},
+			"attributes": {"name": "full name"}
+		}`: func(s string) (*Definition, error) { return LoadJSON(strings.NewReader(s)) },
+		`
+rules:
+  name: required|string|max:3
+  age:
+    - required
+    - min:18
+messages:
+  age.min: You must be an adult.
+attributes:
+  name: full name
+`: func(s string) (*Definition, error) { return LoadYAML(strings.NewReader(s)) },
+	}
+
+	for doc, load := range docs {
+		d, err := load(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(d.Rules) != 2 || d.Rules[0].Attribute != "name" || d.Rules[1].Attribute != "age" {
+			t.Fatalf("expected rules in document order, got %v", d.Rules)
+		}
+
+		errs := d.RuleSet().New(map[string]interface{}{"name": "abcd", "age": 17}).Errors()
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", errs)
+		}
+		if errs[0].Message != "The full name may not be greater than 3 characters." {
+			t.Errorf("unexpected message %q", errs[0].Message)
+		}
+		if errs[1].Message != "You must be an adult." {
+			t.Errorf("unexpected message %q", errs[1].Message)
+		}
+	}
+
+	invalid := map[string]error{
+		`{"rules": {"name": "required|unknown"}}`:         ErrRuleNotSupported,
+		`{"rules": {"name": ""}}`:                         ErrInvalidRule,
+		`{"rules": {"name": 1}}`:                          ErrInvalidRule,
+		`{"rules": {"name": "string", "name": "string"}}`: ErrInvalidRule,
+	}
+	for doc, want := range invalid {
+		if _, err := LoadJSON(strings.NewReader(doc)); !errors.Is(err, want) {
+			t.Errorf("expected %v for %s, got %v", want, doc, err)
+		}
+	}
+
+	if _, err := LoadYAML(strings.NewReader("rules:\n  name: [required, 1]\n")); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule strings follow this grammar:
+//
+//	rules      = rule { "|" rule }
+//	rule       = name [ ":" parameters ]
+//	parameters = parameter { "," parameter }
+//	parameter  = quoted | bare
+//
+// A bare parameter runs up to the next "," or "|" and has its surrounding
+// white space trimmed; \, \| \" and \\ are escape sequences, as in
+// `in:a\,b,c`, other backslashes are kept, as in `in:C:\dir`. A quoted
+// parameter is enclosed in double quotes and may contain any character, as
+// in `in:"a,b",c`; within quotes only \" and \\ are escape sequences, other
+// backslashes are kept.
+//
+// The parameter of regex is not split on commas and its backslashes are kept.
+// It runs up to the next unescaped "|" of a rule string, or to the end of a
+// rule given on its own, as in a []string rule list.
+
+// SyntaxError describes a malformed rule string. Offset is the byte offset of
+// the error in Rule.
+type SyntaxError struct {
+	Rule      string
+	Attribute string
+	Offset    int
+	Msg       string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Attribute != "" {
+		return fmt.Sprintf("validation: syntax error in rule %q of attribute %s at offset %d: %s", e.Rule, e.Attribute, e.Offset, e.Msg)
+	}
+
+	return fmt.Sprintf("validation: syntax error in rule %q at offset %d: %s", e.Rule, e.Offset, e.Msg)
+}
+
+// Unwrap makes syntax errors match ErrInvalidRule.
+func (e *SyntaxError) Unwrap() error {
+	return ErrInvalidRule
+}
+
+// splitRules splits a "|" separated rule string into single rules, keeping
+// their quotes and escape sequences.
+func splitRules(s string) ([]string, error) {
+	rules := []string{}
+	for start := 0; ; {
+		p := &ruleParser{s: s, pos: start, piped: true}
+		if _, _, err := p.parse(); err != nil {
+			return nil, err
+		}
+		rules = append(rules, s[start:p.pos])
+
+		if p.pos == len(s) {
+			return rules, nil
+		}
+		start = p.pos + 1
+	}
+}
+
+// parseSingleRule returns the name and the parameters of a single rule.
+func parseSingleRule(rule string) (string, []string, error) {
+	p := &ruleParser{s: rule}
+	return p.parse()
+}
+
+type ruleParser struct {
+	s     string
+	pos   int
+	piped bool // a "|" ends the rule
+}
+
+func (p *ruleParser) errorf(offset int, format string, args ...interface{}) error {
+	return &SyntaxError{Rule: p.s, Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *ruleParser) atEnd() bool {
+	return p.pos == len(p.s) || p.piped && p.s[p.pos] == '|'
+}
+
+func (p *ruleParser) skipSpaces() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// parse parses the rule starting at p.pos and leaves p.pos at its end.
+func (p *ruleParser) parse() (string, []string, error) {
+	start := p.pos
+	for !p.atEnd() && p.s[p.pos] != ':' {
+		c := p.s[p.pos]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == ' ' || c == '\t') {
+			return "", nil, p.errorf(p.pos, "invalid character %q in rule name", c)
+		}
+		p.pos++
+	}
+
+	name := strings.TrimSpace(p.s[start:p.pos])
+	if name == "" {
+		return "", nil, p.errorf(start, "empty rule")
+	}
+	if strings.ContainsAny(name, " \t") {
+		return "", nil, p.errorf(start+strings.IndexAny(name, " \t"), "invalid white space in rule name")
+	}
+
+	parameters := []string{}
+	if p.atEnd() {
+		return name, parameters, nil
+	}
+	p.pos++ // ':'
+
+	if name == "regex" {
+		parameter, err := p.parseRegex()
+		return name, append(parameters, parameter), err
+	}
+
+	for {
+		parameter, err := p.parseParameter()
+		if err != nil {
+			return "", nil, err
+		}
+		parameters = append(parameters, parameter)
+
+		if p.atEnd() {
+			return name, parameters, nil
+		}
+		p.pos++ // ','
+	}
+}
+
+// parseRegex parses the parameter of the regex rule.
+func (p *ruleParser) parseRegex() (string, error) {
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == '"' {
+		return p.parseParameter()
+	}
+
+	start := p.pos
+	for !p.atEnd() {
+		if p.s[p.pos] == '\\' && p.pos+1 < len(p.s) {
+			p.pos++
+		}
+		p.pos++
+	}
+
+	return strings.TrimSpace(p.s[start:p.pos]), nil
+}
+
+// parseParameter parses a quoted or a bare parameter, up to the "," or "|"
+// that follows it.
+func (p *ruleParser) parseParameter() (string, error) {
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == '"' {
+		return p.parseQuoted()
+	}
+
+	var b strings.Builder
+	for !p.atEnd() && p.s[p.pos] != ',' {
+		if p.s[p.pos] == '\\' && p.pos+1 < len(p.s) && strings.IndexByte(`,|"\\`, p.s[p.pos+1]) != -1 {
+			p.pos++
+		}
+		b.WriteByte(p.s[p.pos])
+		p.pos++
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+func (p *ruleParser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++ // '"'
+
+	var b strings.Builder
+	for {
+		if p.pos == len(p.s) {
+			return "", p.errorf(start, "unterminated quoted parameter")
+		}
+
+		c := p.s[p.pos]
+		if c == '"' {
+			p.pos++
+			break
+		}
+		if c == '\\' && p.pos+1 < len(p.s) && (p.s[p.pos+1] == '"' || p.s[p.pos+1] == '\\') {
+			p.pos++
+			c = p.s[p.pos]
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+
+	p.skipSpaces()
+	if !p.atEnd() && p.s[p.pos] != ',' {
+		return "", p.errorf(p.pos, "unexpected %q after quoted parameter", p.s[p.pos])
+	}
+
+	return b.String(), nil
+}
+
+// escapeParameter escapes the characters that separate rules and parameters,
+// so that s is read back as a single bare parameter.
+func escapeParameter(s string) string {
+	return strings.NewReplacer("\\", "\\\\", ",", "\\,", "|", "\\|", "\"", "\\\"").Replace(s)
+}
+
+// quoteParameter returns s as a quoted parameter, read back unchanged.
+func quoteParameter(s string) string {
+	return `"` + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(s) + `"`
+}
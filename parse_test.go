@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := map[string][][]string{
+		"required|string|max:5":    {{"required"}, {"string"}, {"max", "5"}},
+		"between: 1 , 3":           {{"between", "1", "3"}},
+		`in:"a,b",c`:               {{"in", "a,b", "c"}},
+		`in:"a|b" , "c\"d"|string`: {{"in", "a|b", `c"d`}, {"string"}},
+		`in:a\,b,c\|d`:             {{"in", "a,b", "c|d"}},
+		`in:""`:                    {{"in", ""}},
+		`in:C:\dir,a\\b\"c`:        {{"in", `C:\dir`, `a\b"c`}},
+		`regex:^\d+,\d+$|string`:   {{"regex", `^\d+,\d+$`}, {"string"}},
+		`regex:^a\|b$`:             {{"regex", `^a\|b$`}},
+		`regex:"^(a|b)\d$"|string`: {{"regex", `^(a|b)\d$`}, {"string"}},
+	}
+
+	for s, expected := range tests {
+		rules, err := splitRules(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+
+		parsed := [][]string{}
+		for _, rule := range rules {
+			name, parameters, err := parseSingleRule(rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed = append(parsed, append([]string{name}, parameters...))
+		}
+		if !reflect.DeepEqual(parsed, expected) {
+			t.Errorf("%s: expected %q, got %q", s, expected, parsed)
+		}
+	}
+
+	// A rule given on its own keeps "|" in a regex.
+	if name, parameters, _ := parseSingleRule("regex:^(a|b)$"); name != "regex" || parameters[0] != "^(a|b)$" {
+		t.Errorf("unexpected regex rule %s %q", name, parameters)
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := map[string]int{
+		"required||string": 9,
+		"required|":        9,
+		`in:"a,b`:          3,
+		`in:"a"b`:          6,
+		"max 5":            3,
+		"max-5":            3,
+	}
+
+	for s, offset := range tests {
+		_, err := splitRules(s)
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Offset != offset {
+			t.Errorf("%s: expected a syntax error at offset %d, got %v", s, offset, err)
+		}
+		if !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("%s: expected ErrInvalidRule, got %v", s, err)
+		}
+	}
+
+	defer func() {
+		err, _ := recover().(*SyntaxError)
+		if err == nil || err.Attribute != "foo" || err.Offset != 3 {
+			t.Errorf("expected a syntax error for attribute foo, got %v", err)
+		}
+	}()
+	New(map[string]interface{}{}, map[string]interface{}{"foo": []string{`in:"a`}})
+}
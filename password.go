@@ -0,0 +1,244 @@
+package validation
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultPasswordMin is the minimum length of a password when the password
+// rule has no min requirement.
+const defaultPasswordMin = 8
+
+// passwordPolicy is the policy given by the parameters of the password rule,
+// such as "password:min=12,mixed,numbers,symbols,uncompromised".
+type passwordPolicy struct {
+	min           int
+	letters       bool
+	mixed         bool
+	numbers       bool
+	symbols       bool
+	uncompromised bool
+}
+
+func parsePasswordPolicy(parameters []string) (passwordPolicy, error) {
+	policy := passwordPolicy{min: defaultPasswordMin}
+	for _, parameter := range parameters {
+		name, value, hasValue := strings.Cut(parameter, "=")
+		if hasValue != (name == "min") {
+			return policy, fmt.Errorf("%w: rule password has an invalid requirement %q", ErrInvalidParameter, parameter)
+		}
+
+		switch name {
+		case "min":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return policy, fmt.Errorf("%w: rule password requires a non-negative integer min, got %q", ErrInvalidParameter, value)
+			}
+			policy.min = n
+		case "letters":
+			policy.letters = true
+		case "mixed":
+			policy.mixed = true
+		case "numbers":
+			policy.numbers = true
+		case "symbols":
+			policy.symbols = true
+		case "uncompromised":
+			policy.uncompromised = true
+		default:
+			return policy, fmt.Errorf("%w: rule password has an unknown requirement %q", ErrInvalidParameter, parameter)
+		}
+	}
+
+	return policy, nil
+}
+
+// checkPasswordParameters checks the policy given as parameters of password.
+func checkPasswordParameters(parameters []string) error {
+	_, err := parsePasswordPolicy(parameters)
+	return err
+}
+
+// passwordRequirements returns the requirements of the password rule that
+// the value does not meet, in the order min, letters, mixed, numbers, symbols
+// and uncompromised.
+func passwordRequirements(ctx *Context) []string {
+	policy, err := parsePasswordPolicy(ctx.Parameters)
+	if err != nil {
+		panic(err)
+	}
+	if policy.uncompromised && ctx.breached == nil {
+		panic(fmt.Errorf("%w: rule password requires WithBreachedList for uncompromised", ErrInvalidParameter))
+	}
+
+	password, ok := ctx.Value.(string)
+	if !ok {
+		return []string{"string"}
+	}
+
+	var upper, lower, letter, number, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper, letter = true, true
+		case unicode.IsLower(r):
+			lower, letter = true, true
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsNumber(r):
+			number = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	unmet := []string{}
+	if utf8.RuneCountInString(password) < policy.min {
+		unmet = append(unmet, "min")
+	}
+	if policy.letters && !letter {
+		unmet = append(unmet, "letters")
+	}
+	if policy.mixed && !(upper && lower) {
+		unmet = append(unmet, "mixed")
+	}
+	if policy.numbers && !number {
+		unmet = append(unmet, "numbers")
+	}
+	if policy.symbols && !symbol {
+		unmet = append(unmet, "symbols")
+	}
+	if policy.uncompromised && ctx.breached.Breached(password) {
+		unmet = append(unmet, "uncompromised")
+	}
+
+	return unmet
+}
+
+func validatePassword(ctx *Context) bool {
+	return len(passwordRequirements(ctx)) == 0
+}
+
+// BreachedList is a list of passwords known from data breaches, checked by
+// the uncompromised requirement of the password rule.
+type BreachedList interface {
+	Breached(password string) bool
+}
+
+// HashList is a BreachedList of SHA-1 password hashes held in memory. Use a
+// PrefixDir for the full Pwned Passwords list.
+type HashList struct {
+	hashes map[[sha1.Size]byte]struct{}
+}
+
+// ReadHashList reads a HashList with one hex encoded SHA-1 hash per line,
+// optionally followed by a colon and a count, as in the Pwned Passwords
+// downloads. Blank lines and lines starting with # are ignored.
+func ReadHashList(r io.Reader) (*HashList, error) {
+	list := &HashList{hashes: map[[sha1.Size]byte]struct{}{}}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if i := strings.IndexByte(text, ':'); i != -1 {
+			text = text[:i]
+		}
+
+		var hash [sha1.Size]byte
+		if n, err := hex.Decode(hash[:], []byte(text)); err != nil || n != sha1.Size {
+			return nil, fmt.Errorf("validation: line %d: invalid SHA-1 hash %q", line, text)
+		}
+		list.hashes[hash] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// LoadHashList reads a HashList from a file, see ReadHashList.
+func LoadHashList(path string) (*HashList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadHashList(f)
+}
+
+// Breached reports whether the SHA-1 hash of password is in the list.
+func (l *HashList) Breached(password string) bool {
+	_, ok := l.hashes[sha1.Sum([]byte(password))]
+	return ok
+}
+
+// Len returns the number of hashes in the list.
+func (l *HashList) Len() int {
+	return len(l.hashes)
+}
+
+// PrefixDir is a BreachedList read on demand from a directory of hash prefix
+// files, as written by the Pwned Passwords downloader: the file
+// "<dir>/21BD1.txt" lists the SHA-1 hashes starting with 21BD1, one
+// 35 character hex suffix per line, optionally followed by a colon and a
+// count. Only the file of the prefix of a password is read, so the list is
+// never loaded in memory.
+//
+// A missing prefix file holds no hashes. A prefix file that cannot be read
+// fails closed: the password is reported as breached.
+type PrefixDir struct {
+	dir string
+}
+
+// OpenPrefixDir returns the PrefixDir of a directory.
+func OpenPrefixDir(dir string) (*PrefixDir, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("validation: %s is not a directory", dir)
+	}
+
+	return &PrefixDir{dir: dir}, nil
+}
+
+// Breached reports whether the SHA-1 hash of password is in the prefix file
+// of its first 5 hex digits.
+func (d *PrefixDir) Breached(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	f, err := os.Open(filepath.Join(d.dir, hash[:5]+".txt"))
+	if err != nil {
+		return !os.IsNotExist(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		suffix := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(suffix, ':'); i != -1 {
+			suffix = suffix[:i]
+		}
+		if strings.EqualFold(suffix, hash[5:]) {
+			return true
+		}
+	}
+
+	return scanner.Err() != nil
+}
@@ -0,0 +1,140 @@
+package validation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPasswordRule(t *testing.T) {
+	tests := map[string]struct {
+		password interface{}
+		rule     string
+		unmet    []string
+	}{
+		"default-min":    {"secret", "password", []string{"min"}},
+		"default-pass":   {"secret12", "password", nil},
+		"min":            {"Secret1!", "password:min=12", []string{"min"}},
+		"all":            {"correct horse Battery 9", "password:min=12,mixed,numbers,symbols", nil},
+		"letters":        {"12345678", "password:letters", []string{"letters"}},
+		"mixed":          {"lowercase", "password:mixed", []string{"mixed"}},
+		"several":        {"short", "password:min=12,mixed,numbers,symbols", []string{"min", "mixed", "numbers", "symbols"}},
+		"unicode-length": {"ääääääää", "password:min=8", nil},
+		"not-a-string":   {12345678, "password", []string{"string"}},
+	}
+
+	for name, tt := range tests {
+		errs := New(map[string]interface{}{"password": tt.password}, map[string]interface{}{"password": tt.rule}).Errors()
+		if len(errs) != len(tt.unmet) {
+			t.Errorf("%s: expected %d errors, got %v", name, len(tt.unmet), errs)
+			continue
+		}
+		for i, err := range errs {
+			if err.Rule != "password" || err.Meta["requirement"] != tt.unmet[i] {
+				t.Errorf("%s: expected unmet requirement %s, got %v", name, tt.unmet[i], err.Meta)
+			}
+		}
+	}
+}
+
+func TestPasswordMessages(t *testing.T) {
+	validator := New(
+		map[string]interface{}{"password": "short"},
+		map[string]interface{}{"password": "password:min=10,numbers"},
+		WithMessages(map[string]string{"password:numbers": "Add a digit to the :attribute."}),
+	)
+
+	errs := validator.Errors()
+	if len(errs) != 2 ||
+		errs[0].Message != "The password must be at least 10 characters." ||
+		errs[1].Message != "Add a digit to the password." {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	// "password.min" is the message of the min rule of a password attribute,
+	// not of the min requirement of the password rule.
+	messages := map[string]string{
+		"password.min":                  "Your password must be 12 chars.",
+		"new_password.password:symbols": "Add a symbol to the :attribute.",
+	}
+	errs = New(
+		map[string]interface{}{"new_password": "short"},
+		map[string]interface{}{"new_password": "password:min=8,symbols"},
+		WithMessages(messages),
+	).Errors()
+	if len(errs) != 2 ||
+		errs[0].Message != "The new_password must be at least 8 characters." ||
+		errs[1].Message != "Add a symbol to the new_password." {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestBreachedList(t *testing.T) {
+	// The SHA-1 hash of "password1", in upper case, and another hash.
+	list, err := ReadHashList(strings.NewReader(`# breached
+E38AD214943DAAD1D64C102FAEC29DE4AFE9DA3D:2413945
+
+1e0c9e8b6f86d29a1b8c34d9a8c4b7bfa4d5d2fe:1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Len() != 2 || !list.Breached("password1") || list.Breached("password2") {
+		t.Errorf("unexpected hash list %v", list.hashes)
+	}
+
+	rules := map[string]interface{}{"password": "password:uncompromised"}
+	validator := New(map[string]interface{}{"password": "password1"}, rules, WithBreachedList(list))
+	if validator.GetMessage() != "The given password has appeared in a data leak. Please choose a different password." {
+		t.Errorf("unexpected message %q", validator.GetMessage())
+	}
+	if !New(map[string]interface{}{"password": "password2"}, rules, WithBreachedList(list)).Passes() {
+		t.Errorf("expected a password that is not in the list to pass")
+	}
+
+	err = New(map[string]interface{}{"password": "password1"}, rules).Validate()
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter without a breached list, got %v", err)
+	}
+
+	if _, err := ReadHashList(strings.NewReader("not a hash\n")); err == nil {
+		t.Errorf("expected an error for an invalid hash")
+	}
+}
+
+func TestPasswordInvalidPolicy(t *testing.T) {
+	for _, rule := range []string{"password:min", "password:min=x", "password:long", "password:mixed=1"} {
+		err := New(map[string]interface{}{"password": "secret"}, map[string]interface{}{"password": rule}).Validate()
+		if !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("%s: expected ErrInvalidParameter, got %v", rule, err)
+		}
+	}
+}
+
+func TestPrefixDir(t *testing.T) {
+	// The prefix file of "password1", whose SHA-1 hash is E38AD214943DAAD1....
+	dir := t.TempDir()
+	data := "0018A45C4D1DEF81644B54AB7F969B88D65:1\n214943daad1d64c102faec29de4afe9da3d:2413945\n"
+	if err := os.WriteFile(filepath.Join(dir, "E38AD.txt"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := OpenPrefixDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !list.Breached("password1") || list.Breached("password2") {
+		t.Errorf("unexpected prefix dir results")
+	}
+
+	rules := map[string]interface{}{"password": "password:uncompromised"}
+	if New(map[string]interface{}{"password": "password1"}, rules, WithBreachedList(list)).Passes() {
+		t.Errorf("expected a breached password to fail")
+	}
+
+	if _, err := OpenPrefixDir(filepath.Join(dir, "E38AD.txt")); err == nil {
+		t.Errorf("expected an error for a file")
+	}
+}
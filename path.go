@@ -0,0 +1,251 @@
+package validation
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// target is an attribute to validate: a concrete path such as "tags.0" and
+// the declared attribute it was expanded from, such as "tags.*".
+type target struct {
+	attribute string
+	pattern   string
+}
+
+// targets expands the wildcards of the declared attributes against the data.
+func (v *validator) targets() []target {
+	targets := make([]target, 0, len(v.ruleSet.attributes))
+	for _, pattern := range v.ruleSet.attributes {
+		if strings.Index(pattern, "*") == -1 {
+			targets = append(targets, target{pattern, pattern})
+			continue
+		}
+		for _, attribute := range expandPath(v.data, "", strings.Split(pattern, ".")) {
+			targets = append(targets, target{attribute, pattern})
+		}
+	}
+
+	return targets
+}
+
+func expandPath(node interface{}, prefix string, segments []string) []string {
+	if len(segments) == 0 {
+		return []string{prefix}
+	}
+
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	if segments[0] != "*" {
+		child, _ := getChild(node, segments[0])
+		return expandPath(child, join(segments[0]), segments[1:])
+	}
+
+	paths := []string{}
+	rv := reflect.ValueOf(node)
+	switch rv.Kind() {
+	case reflect.Map:
+		keys := make([]string, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			if key.Kind() == reflect.String {
+				keys = append(keys, key.String())
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child, _ := getChild(node, key)
+			paths = append(paths, expandPath(child, join(key), segments[1:])...)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			paths = append(paths, expandPath(rv.Index(i).Interface(), join(strconv.Itoa(i)), segments[1:])...)
+		}
+	}
+
+	return paths
+}
+
+// getValue returns the value of attribute. An attribute that is not a key of
+// the data is treated as a dot separated path into nested maps and slices.
+func (v *validator) getValue(attribute string) interface{} {
+	return getPath(v.data, attribute)
+}
+
+func getPath(data map[string]interface{}, attribute string) interface{} {
+	if value, ok := data[attribute]; ok {
+		return value
+	}
+	if strings.Index(attribute, ".") == -1 {
+		return nil
+	}
+
+	var node interface{} = data
+	for _, segment := range strings.Split(attribute, ".") {
+		var ok bool
+		if node, ok = getChild(node, segment); !ok {
+			return nil
+		}
+	}
+
+	return node
+}
+
+// getChild returns the element of a map or slice for a path segment.
+func getChild(node interface{}, segment string) (interface{}, bool) {
+	if node == nil {
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(node)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		value := rv.MapIndex(reflect.ValueOf(segment).Convert(rv.Type().Key()))
+		if !value.IsValid() {
+			return nil, false
+		}
+		return value.Interface(), true
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(segment)
+		if err != nil || i < 0 || i >= rv.Len() {
+			return nil, false
+		}
+		return rv.Index(i).Interface(), true
+	default:
+		return nil, false
+	}
+}
+
+// copyPath copies the value of attribute from data to dst, creating the maps
+// and slices holding it. A missing attribute is not copied.
+func copyPath(dst, data map[string]interface{}, attribute string) {
+	if value, ok := data[attribute]; ok {
+		dst[attribute] = value
+		return
+	}
+
+	segments := strings.Split(attribute, ".")
+	if _, ok := getChild(data, segments[0]); ok {
+		dst[segments[0]] = copySegments(dst[segments[0]], data[segments[0]], segments[1:])
+	}
+}
+
+// copySegments returns dst with the value at segments of src copied into it.
+func copySegments(dst, src interface{}, segments []string) interface{} {
+	if len(segments) == 0 {
+		return src
+	}
+
+	child, ok := getChild(src, segments[0])
+	if !ok {
+		return dst
+	}
+
+	rv := reflect.ValueOf(src)
+	switch rv.Kind() {
+	case reflect.Map:
+		m, ok := dst.(map[string]interface{})
+		if !ok {
+			m = map[string]interface{}{}
+		}
+		m[segments[0]] = copySegments(m[segments[0]], child, segments[1:])
+		return m
+	default:
+		s, ok := dst.([]interface{})
+		if !ok {
+			s = make([]interface{}, rv.Len())
+		}
+		i, _ := strconv.Atoi(segments[0])
+		s[i] = copySegments(s[i], child, segments[1:])
+		return s
+	}
+}
+
+// replacePath returns a copy of node with the value at segments replaced,
+// copying the maps and slices along the path so node is not modified. A
+// missing path, or one through other types of containers, is left as is.
+func replacePath(node interface{}, segments []string, value interface{}) interface{} {
+	if len(segments) == 0 {
+		return value
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[segments[0]]
+		if !ok {
+			return node
+		}
+		m := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			m[k] = v
+		}
+		m[segments[0]] = replacePath(child, segments[1:], value)
+		return m
+	case []interface{}:
+		i, err := strconv.Atoi(segments[0])
+		if err != nil || i < 0 || i >= len(n) {
+			return node
+		}
+		s := append([]interface{}{}, n...)
+		s[i] = replacePath(n[i], segments[1:], value)
+		return s
+	default:
+		return node
+	}
+}
+
+// removePath returns a copy of node without the value at segments, copying
+// the maps and slices along the path so node is not modified. A slice
+// element is removed from the slice.
+func removePath(node interface{}, segments []string) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[segments[0]]
+		if !ok {
+			return node
+		}
+		m := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			m[k] = v
+		}
+		if len(segments) == 1 {
+			delete(m, segments[0])
+		} else {
+			m[segments[0]] = removePath(child, segments[1:])
+		}
+		return m
+	case []interface{}:
+		i, err := strconv.Atoi(segments[0])
+		if err != nil || i < 0 || i >= len(n) {
+			return node
+		}
+		if len(segments) == 1 {
+			return append(append([]interface{}{}, n[:i]...), n[i+1:]...)
+		}
+		s := append([]interface{}{}, n...)
+		s[i] = removePath(n[i], segments[1:])
+		return s
+	default:
+		return node
+	}
+}
+
+// hasIncludedAncestor reports whether an ancestor of attribute, which is
+// copied whole, is in included.
+func hasIncludedAncestor(included map[string]bool, attribute string) bool {
+	for i := strings.LastIndex(attribute, "."); i > 0; i = strings.LastIndex(attribute[:i], ".") {
+		if included[attribute[:i]] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType is the media type of an RFC 9457 problem details document.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details document carrying the invalid fields
+// in the "errors" extension member.
+type Problem struct {
+	Type     string           `json:"type"`
+	Title    string           `json:"title"`
+	Status   int              `json:"status"`
+	Detail   string           `json:"detail,omitempty"`
+	Instance string           `json:"instance,omitempty"`
+	Errors   ValidationErrors `json:"errors"`
+}
+
+// NewProblem returns a 422 Unprocessable Content problem for errs.
+func NewProblem(errs ValidationErrors) *Problem {
+	if errs == nil {
+		errs = ValidationErrors{}
+	}
+
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusUnprocessableEntity),
+		Status: http.StatusUnprocessableEntity,
+		Detail: "The given data was invalid.",
+		Errors: errs,
+	}
+}
+
+// WriteProblem writes errs to w as an application/problem+json response.
+func WriteProblem(w http.ResponseWriter, errs ValidationErrors) error {
+	problem := NewProblem(errs)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+
+	return json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteProblem(t *testing.T) {
+	validator := New(
+		map[string]interface{}{"foo": "aPz", "bar": 5},
+		map[string]interface{}{"foo": "required|string|max:2", "bar": "in:1,2"},
+	)
+
+	errs := validator.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(errs))
+	}
+
+	w := httptest.NewRecorder()
+	if err := WriteProblem(w, errs); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Code != 422 {
+		t.Errorf("expected status 422, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("expected content type %s, got %s", ProblemContentType, ct)
+	}
+
+	var problem struct {
+		Status int `json:"status"`
+		Errors []struct {
+			Field   string   `json:"field"`
+			Rule    string   `json:"rule"`
+			Params  []string `json:"params"`
+			Message string   `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+
+	if problem.Status != 422 || len(problem.Errors) != 2 {
+		t.Fatalf("unexpected problem %s", w.Body.String())
+	}
+	for _, e := range problem.Errors {
+		switch e.Field {
+		case "foo":
+			if e.Rule != "max" || len(e.Params) != 1 || e.Params[0] != "2" || e.Message != "The foo may not be greater than 2 characters." {
+				t.Errorf("unexpected error for foo: %+v", e)
+			}
+		case "bar":
+			if e.Rule != "in" || e.Message != "The bar field must one of (1,2)." {
+				t.Errorf("unexpected error for bar: %+v", e)
+			}
+		default:
+			t.Errorf("unexpected field %s", e.Field)
+		}
+	}
+}
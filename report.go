@@ -0,0 +1,212 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// Report accumulates the results of many validations, such as the records of
+// a stream or of ValidateMany, and counts the failures of each attribute and
+// rule pair. Attributes are grouped as declared in the rules, so the failures
+// of "items.0.id" and "items.1.id" count for "items.*.id". It is safe for
+// concurrent use.
+type Report struct {
+	samples int
+
+	mu       sync.Mutex
+	records  int
+	failed   int
+	failures map[[2]string]*ReportEntry
+}
+
+// ReportEntry holds the failures of an attribute and rule pair. The values
+// of sensitive attributes, such as passwords and card numbers, are never kept
+// as samples.
+type ReportEntry struct {
+	Attribute string        `json:"attribute"`
+	Rule      string        `json:"rule"`
+	Count     int           `json:"count"`
+	Samples   []interface{} `json:"samples,omitempty"`
+}
+
+// NewReport returns an empty report keeping up to samples failing values of
+// each attribute and rule pair.
+func NewReport(samples int) *Report {
+	return &Report{
+		samples:  samples,
+		failures: map[[2]string]*ReportEntry{},
+	}
+}
+
+// Add records the result of validating data: err is nil or the
+// ValidationErrors returned by Validate. Any other error, such as a
+// configuration error, fails the record and is counted under the rule
+// "error" without an attribute, with the error messages as samples.
+func (r *Report) Add(data map[string]interface{}, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records++
+	var errs ValidationErrors
+	if err != nil && !errors.As(err, &errs) {
+		r.failed++
+		r.addFailure("", "error", err.Error(), true)
+		return
+	}
+	if len(errs) == 0 {
+		return
+	}
+
+	r.failed++
+	for _, e := range errs {
+		attribute := e.Pattern
+		if attribute == "" {
+			attribute = e.Field
+		}
+		r.addFailure(attribute, e.Rule, getPath(data, e.Field), !e.Sensitive)
+	}
+}
+
+func (r *Report) addFailure(attribute, rule string, sample interface{}, sampled bool) {
+	key := [2]string{attribute, rule}
+	entry, ok := r.failures[key]
+	if !ok {
+		entry = &ReportEntry{Attribute: attribute, Rule: rule}
+		r.failures[key] = entry
+	}
+
+	entry.Count++
+	if sampled && len(entry.Samples) < r.samples {
+		entry.Samples = append(entry.Samples, sample)
+	}
+}
+
+// Records returns the number of validations added to the report.
+func (r *Report) Records() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.records
+}
+
+// Failed returns the number of failed validations.
+func (r *Report) Failed() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.failed
+}
+
+// PassRate returns the fraction of valid records, 1 for an empty report.
+func (r *Report) PassRate() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.passRate()
+}
+
+func (r *Report) passRate() float64 {
+	if r.records == 0 {
+		return 1
+	}
+
+	return float64(r.records-r.failed) / float64(r.records)
+}
+
+// Entries returns the failures of each attribute and rule pair, the most
+// frequent first.
+func (r *Report) Entries() []ReportEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.entries()
+}
+
+func (r *Report) entries() []ReportEntry {
+	entries := make([]ReportEntry, 0, len(r.failures))
+	for _, entry := range r.failures {
+		e := *entry
+		e.Samples = append([]interface{}{}, entry.Samples...)
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		if entries[i].Attribute != entries[j].Attribute {
+			return entries[i].Attribute < entries[j].Attribute
+		}
+		return entries[i].Rule < entries[j].Rule
+	})
+
+	return entries
+}
+
+// WriteText writes the report as an aligned text table.
+func (r *Report) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Records: %d, failed: %d, pass rate: %.2f%%\n", r.records, r.failed, r.passRate()*100)
+	if len(r.failures) > 0 {
+		fmt.Fprintln(tw, "\nATTRIBUTE\tRULE\tFAILURES\tSAMPLES")
+		for _, entry := range r.entries() {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", entry.Attribute, entry.Rule, entry.Count, formatSamples(entry.Samples))
+		}
+	}
+
+	return tw.Flush()
+}
+
+// WriteJSON writes the report as a JSON object.
+func (r *Report) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return json.NewEncoder(w).Encode(struct {
+		Records  int           `json:"records"`
+		Failed   int           `json:"failed"`
+		PassRate float64       `json:"pass_rate"`
+		Failures []ReportEntry `json:"failures"`
+	}{r.records, r.failed, r.passRate(), r.entries()})
+}
+
+// WriteMarkdown writes the report as a Markdown table.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Records:** %d, **failed:** %d, **pass rate:** %.2f%%\n", r.records, r.failed, r.passRate()*100)
+	if len(r.failures) > 0 {
+		b.WriteString("\n| Attribute | Rule | Failures | Samples |\n|---|---|---:|---|\n")
+		for _, entry := range r.entries() {
+			samples := strings.Replace(formatSamples(entry.Samples), "|", "\\|", -1)
+			fmt.Fprintf(&b, "| %s | %s | %d | %s |\n", entry.Attribute, entry.Rule, entry.Count, samples)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatSamples(samples []interface{}) string {
+	s := make([]string, len(samples))
+	for i, sample := range samples {
+		if sample == nil {
+			s[i] = "(missing)"
+		} else {
+			s[i] = fmt.Sprintf("%q", fmt.Sprint(sample))
+		}
+	}
+
+	return strings.Join(s, ", ")
+}
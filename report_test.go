@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	records := []map[string]interface{}{
+		{"name": "foo", "age": 20.0},
+		{"name": "f00", "age": 20.0},
+		{"age": 10.0},
+		{"name": "b4r", "age": 30.0},
+	}
+	ruleSet := NewRuleSet(map[string]interface{}{"name": "required|alpha", "age": "required|min:18"})
+
+	report := NewReport(1)
+	for i, err := range ruleSet.ValidateMany(records) {
+		report.Add(records[i], err)
+	}
+
+	if report.Records() != 4 || report.Failed() != 3 || report.PassRate() != 0.25 {
+		t.Errorf("unexpected totals %d, %d, %f", report.Records(), report.Failed(), report.PassRate())
+	}
+
+	entries := report.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", entries)
+	}
+	if e := entries[0]; e.Attribute != "name" || e.Rule != "alpha" || e.Count != 2 || len(e.Samples) != 1 || e.Samples[0] != "f00" {
+		t.Errorf("unexpected first entry %+v", e)
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	expected := "Records: 4, failed: 3, pass rate: 25.00%\n" +
+		"\n" +
+		"ATTRIBUTE  RULE      FAILURES  SAMPLES\n" +
+		"name       alpha     2         \"f00\"\n" +
+		"age        min       1         \"10\"\n" +
+		"name       required  1         (missing)\n"
+	if text.String() != expected {
+		t.Errorf("unexpected text report:\n%s", text.String())
+	}
+
+	var markdown bytes.Buffer
+	if err := report.WriteMarkdown(&markdown); err != nil {
+		t.Fatal(err)
+	}
+	expected = "**Records:** 4, **failed:** 3, **pass rate:** 25.00%\n" +
+		"\n" +
+		"| Attribute | Rule | Failures | Samples |\n" +
+		"|---|---|---:|---|\n" +
+		"| name | alpha | 2 | \"f00\" |\n" +
+		"| age | min | 1 | \"10\" |\n" +
+		"| name | required | 1 | (missing) |\n"
+	if markdown.String() != expected {
+		t.Errorf("unexpected markdown report:\n%s", markdown.String())
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Records  int           `json:"records"`
+		PassRate float64       `json:"pass_rate"`
+		Failures []ReportEntry `json:"failures"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Records != 4 || decoded.PassRate != 0.25 || len(decoded.Failures) != 3 {
+		t.Errorf("unexpected JSON report %s", buf.String())
+	}
+}
+
+func TestReportPatternsAndErrors(t *testing.T) {
+	ruleSet := NewRuleSet(map[string]interface{}{"items.*.id": "required|integer"})
+	records := []map[string]interface{}{
+		{"items": []interface{}{map[string]interface{}{"id": "a"}, map[string]interface{}{"id": "b"}}},
+		{"items": []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": "c"}}},
+	}
+
+	report := NewReport(2)
+	for i, err := range ruleSet.ValidateMany(records) {
+		report.Add(records[i], err)
+	}
+	report.Add(nil, ValidationErrors(nil))
+	report.Add(nil, ErrInvalidParameter)
+
+	if report.Records() != 4 || report.Failed() != 3 {
+		t.Errorf("unexpected totals %d, %d", report.Records(), report.Failed())
+	}
+
+	entries := report.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	if e := entries[0]; e.Attribute != "items.*.id" || e.Rule != "integer" || e.Count != 3 || len(e.Samples) != 2 || e.Samples[0] == nil {
+		t.Errorf("unexpected pattern entry %+v", e)
+	}
+	if e := entries[1]; e.Attribute != "" || e.Rule != "error" || e.Count != 1 || e.Samples[0] != ErrInvalidParameter.Error() {
+		t.Errorf("unexpected error entry %+v", e)
+	}
+}
+
+func TestReportSensitive(t *testing.T) {
+	ruleSet := NewRuleSet(map[string]interface{}{"pw": "password:min=8", "card": "required|credit_card", "name": "alpha"})
+	records := []map[string]interface{}{{"pw": "hunter2", "card": "4111 1111 1111 1112", "name": "b4r"}}
+
+	report := NewReport(3)
+	for i, err := range ruleSet.ValidateMany(records) {
+		report.Add(records[i], err)
+	}
+
+	entries := report.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", entries)
+	}
+	for _, e := range entries {
+		if (e.Attribute == "name") != (len(e.Samples) == 1) {
+			t.Errorf("unexpected samples for %s: %v", e.Attribute, e.Samples)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package validation
+
+import (
+	"fmt"
+)
+
+// Rule is a validation rule implemented in Go. A Rule, a ContextRule, or a
+// function of type func(string, interface{}) bool, func(string, interface{})
+// error or func(*Context) error can be given in place of a rule string, or
+// mixed with rule strings in a []interface{} rule list:
+//
+//	validation.New(data, map[string]interface{}{
+//		"code": []interface{}{"required", "string", func(attribute string, value interface{}) bool {
+//			return strings.HasPrefix(value.(string), "X-")
+//		}},
+//	})
+//
+// A function returning bool fails with the message "The :attribute is
+// invalid.", a function returning an error fails with the error message.
+type Rule interface {
+	// Passes reports whether value is valid for attribute.
+	Passes(attribute string, value interface{}) bool
+
+	// Message returns the message of a failure. It may use the :attribute
+	// placeholder.
+	Message() string
+}
+
+// NamedRule is implemented by a Rule or ContextRule that reports its
+// failures under a rule name, used as FieldError.Rule and to look up custom
+// messages. Other rules implemented in Go are named "custom".
+type NamedRule interface {
+	Name() string
+}
+
+// ImplicitRule is implemented by a rule implemented in Go that applies to
+// missing values. Other rules pass for a missing value, as the built-in rules
+// except required do.
+type ImplicitRule interface {
+	Implicit() bool
+}
+
+// Implicit marks a rule implemented in Go, typically a function, as implicit.
+func Implicit(rule interface{}) interface{} {
+	return implicitRule{rule}
+}
+
+type implicitRule struct {
+	rule interface{}
+}
+
+// RuleLister is implemented by rule builders, such as those of the rules
+// package, and can be used in place of a rule string.
+type RuleLister interface {
+	Rules() []string
+}
+
+// rule is a parsed rule of an attribute: a rule string or a rule
+// implemented in Go.
+type rule struct {
+	raw        string // the rule string, empty for a rule implemented in Go
+	name       string
+	parameters []string
+	custom     func(ctx *Context) error
+	implicit   bool
+}
+
+func explodeRules(rules map[string]interface{}) map[string][]rule {
+	r := map[string][]rule{}
+
+	for attribute, rule := range rules {
+		r[attribute] = explodeRule(attribute, rule)
+	}
+
+	return r
+}
+
+func explodeRule(attribute string, rule interface{}) []rule {
+	r, err := toRules(attribute, rule)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// toRules parses the rules of an attribute: a rule string, a []string, a
+// RuleLister, a rule implemented in Go, or a []interface{} of those.
+func toRules(attribute string, value interface{}) ([]rule, error) {
+	r := []rule{}
+
+	var err error
+	switch value.(type) {
+	case string:
+		var strRules []string
+		if strRules, err = splitRules(value.(string)); err == nil {
+			r, err = appendStringRules(r, strRules)
+		}
+	case []string:
+		r, err = appendStringRules(r, value.([]string))
+	case RuleLister:
+		r, err = appendStringRules(r, value.(RuleLister).Rules())
+	case []interface{}:
+		for _, item := range value.([]interface{}) {
+			switch item.(type) {
+			case string:
+				r, err = appendStringRules(r, []string{item.(string)})
+			case RuleLister:
+				r, err = appendStringRules(r, item.(RuleLister).Rules())
+			default:
+				custom, ok := toCustomRule(item)
+				if !ok {
+					return nil, fmt.Errorf("%w: invalid rule type %T for attribute %s", ErrInvalidRule, item, attribute)
+				}
+				r = append(r, custom)
+			}
+			if err != nil {
+				break
+			}
+		}
+	default:
+		custom, ok := toCustomRule(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid rule type %T for attribute %s", ErrInvalidRule, value, attribute)
+		}
+		r = append(r, custom)
+	}
+
+	if err != nil {
+		err.(*SyntaxError).Attribute = attribute
+		return nil, err
+	}
+	if !isValidRules(r) {
+		return nil, fmt.Errorf("%w: invalid rule for attribute %s", ErrInvalidRule, attribute)
+	}
+
+	return r, nil
+}
+
+func appendStringRules(r []rule, strRules []string) ([]rule, error) {
+	for _, strRule := range strRules {
+		name, parameters, err := parseSingleRule(strRule)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, rule{raw: strRule, name: name, parameters: parameters, implicit: implicitRules[name]})
+	}
+
+	return r, nil
+}
+
+func toCustomRule(value interface{}) (rule, bool) {
+	r := rule{name: "custom"}
+	if marked, ok := value.(implicitRule); ok {
+		value = marked.rule
+		r.implicit = true
+	}
+	if named, ok := value.(NamedRule); ok {
+		r.name = named.Name()
+	}
+	if implicit, ok := value.(ImplicitRule); ok {
+		r.implicit = r.implicit || implicit.Implicit()
+	}
+
+	switch value.(type) {
+	case ContextRule:
+		r.custom = value.(ContextRule).Validate
+	case Rule:
+		custom := value.(Rule)
+		r.custom = func(ctx *Context) error {
+			if custom.Passes(ctx.Attribute, ctx.Value) {
+				return nil
+			}
+			return &Failure{Message: custom.Message()}
+		}
+	case func(*Context) error:
+		r.custom = value.(func(*Context) error)
+	case func(string, interface{}) bool:
+		f := value.(func(string, interface{}) bool)
+		r.custom = func(ctx *Context) error {
+			if f(ctx.Attribute, ctx.Value) {
+				return nil
+			}
+			return &Failure{}
+		}
+	case func(string, interface{}) error:
+		f := value.(func(string, interface{}) error)
+		r.custom = func(ctx *Context) error {
+			return f(ctx.Attribute, ctx.Value)
+		}
+	default:
+		return rule{}, false
+	}
+
+	return r, true
+}
+
+func isValidRules(rules []rule) bool {
+	return len(rules) > 0
+}
@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+)
+
+// RuleSet is a parsed set of rules. It is safe for concurrent use and can
+// validate any number of records without parsing the rules again.
+type RuleSet struct {
+	attributes []string
+	rules      map[string][]rule
+	options    []Option
+}
+
+// AttributeRules holds the rules of one attribute of an ordered rule list.
+// Rules is any rule value accepted in the rules map passed to New.
+type AttributeRules struct {
+	Attribute string
+	Rules     interface{}
+}
+
+// NewRuleSet parses rules. Attributes are validated in the sorted order of
+// their names.
+func NewRuleSet(rules map[string]interface{}, opts ...Option) *RuleSet {
+	attributes := make([]string, 0, len(rules))
+	for attribute := range rules {
+		attributes = append(attributes, attribute)
+	}
+	sort.Strings(attributes)
+
+	return &RuleSet{
+		attributes: attributes,
+		rules:      explodeRules(rules),
+		options:    opts,
+	}
+}
+
+// NewOrderedRuleSet parses rules. Attributes are validated in the order they
+// are declared.
+func NewOrderedRuleSet(rules []AttributeRules, opts ...Option) *RuleSet {
+	attributes := make([]string, 0, len(rules))
+	r := map[string][]rule{}
+
+	for _, attributeRules := range rules {
+		if _, ok := r[attributeRules.Attribute]; ok {
+			panic(fmt.Errorf("%w: duplicate rules for attribute %s", ErrInvalidRule, attributeRules.Attribute))
+		}
+		attributes = append(attributes, attributeRules.Attribute)
+		r[attributeRules.Attribute] = explodeRule(attributeRules.Attribute, attributeRules.Rules)
+	}
+
+	return &RuleSet{
+		attributes: attributes,
+		rules:      r,
+		options:    opts,
+	}
+}
+
+// New returns a validator for data.
+func (rs *RuleSet) New(data map[string]interface{}) *validator {
+	v := &validator{
+		data:    data,
+		ruleSet: rs,
+		ctx:     context.Background(),
+	}
+	for _, opt := range rs.options {
+		opt(v)
+	}
+
+	return v
+}
+
+// ValidateMany validates records across all CPUs and returns the result of
+// Validate for each record, in input order. A panic raised by a rule is raised
+// again in the calling goroutine once all workers are done.
+func (rs *RuleSet) ValidateMany(records []map[string]interface{}) []error {
+	results := make([]error, len(records))
+	parallel(runtime.GOMAXPROCS(0), len(records), func(i int) {
+		results[i] = rs.New(records[i]).Validate()
+	})
+
+	return results
+}
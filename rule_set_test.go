@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestConcurrency(t *testing.T) {
+	data := map[string]interface{}{}
+	rules := map[string]interface{}{}
+	for i := 0; i < 100; i++ {
+		attribute := fmt.Sprintf("foo%03d", i)
+		data[attribute] = i
+		rules[attribute] = "max:49"
+	}
+
+	errs := New(data, rules, WithConcurrency(8)).Errors()
+	if len(errs) != 50 {
+		t.Fatalf("expected 50 errors, got %d", len(errs))
+	}
+	for i, err := range errs {
+		if err.Field != fmt.Sprintf("foo%03d", i+50) {
+			t.Fatalf("expected errors in attribute order, got %s at %d", err.Field, i)
+		}
+	}
+
+	err := New(data, map[string]interface{}{"foo000": "max:a", "foo001": "max:1"}, WithConcurrency(2)).Validate()
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestValidateMany(t *testing.T) {
+	records := make([]map[string]interface{}, 1000)
+	for i := range records {
+		records[i] = map[string]interface{}{"foo": i % 10}
+	}
+
+	results := NewRuleSet(map[string]interface{}{"foo": "required|in:1,2,3"}).ValidateMany(records)
+	if len(results) != len(records) {
+		t.Fatalf("expected %d results, got %d", len(records), len(results))
+	}
+	for i, err := range results {
+		pass := i%10 >= 1 && i%10 <= 3
+		if (err == nil) != pass {
+			t.Errorf("unexpected result for record %d: %v", i, err)
+		}
+	}
+}
+
+func TestValidateManyPanic(t *testing.T) {
+	ruleSet := NewRuleSet(map[string]interface{}{
+		"n": func(attribute string, value interface{}) bool {
+			if value == 3 {
+				panic("rule failed")
+			}
+			return true
+		},
+	})
+
+	records := make([]map[string]interface{}, 10)
+	for i := range records {
+		records[i] = map[string]interface{}{"n": i}
+	}
+
+	defer func() {
+		if r := recover(); r != "rule failed" {
+			t.Errorf("expected the panic of the rule, got %v", r)
+		}
+	}()
+	ruleSet.ValidateMany(records)
+	t.Errorf("expected a panic")
+}
+
+func TestNewOrderedRuleSetDuplicate(t *testing.T) {
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("expected a panic wrapping ErrInvalidRule, got %v", err)
+		}
+	}()
+
+	NewOrderedRuleSet([]AttributeRules{{"foo", "required"}, {"foo", "string"}})
+}
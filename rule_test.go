@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type prefixRule string
+
+func (r prefixRule) Passes(attribute string, value interface{}) bool {
+	s, _ := value.(string)
+	return strings.HasPrefix(s, string(r))
+}
+
+func (r prefixRule) Message() string {
+	return "The :attribute must start with " + string(r) + "."
+}
+
+func (r prefixRule) Name() string {
+	return "prefix"
+}
+
+func TestCustomRules(t *testing.T) {
+	even := func(attribute string, value interface{}) bool {
+		n, _ := value.(int)
+		return n%2 == 0
+	}
+	notFoo := func(attribute string, value interface{}) error {
+		if value == "foo" {
+			return errors.New("foo is not allowed as " + attribute + ".")
+		}
+		return nil
+	}
+
+	rules := map[string]interface{}{
+		"code":  []interface{}{"required", "string", prefixRule("X-"), "max:5"},
+		"count": even,
+		"name":  []interface{}{"required", notFoo},
+	}
+
+	if err := New(map[string]interface{}{"code": "X-12", "count": 2, "name": "bar"}, rules).Validate(); err != nil {
+		t.Errorf("expected valid data, got %v", err)
+	}
+
+	errs := New(map[string]interface{}{"code": "Y-12", "count": 3, "name": "foo"}, rules).Errors()
+	expected := []FieldError{
+		{Field: "code", Rule: "prefix", Message: "The code must start with X-."},
+		{Field: "count", Rule: "custom", Message: "The count is invalid."},
+		{Field: "name", Rule: "custom", Message: "foo is not allowed as name."},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for i, err := range errs {
+		if err.Field != expected[i].Field || err.Rule != expected[i].Rule || err.Message != expected[i].Message {
+			t.Errorf("expected %+v, got %+v", expected[i], *err)
+		}
+	}
+
+	errs = New(map[string]interface{}{"code": "X-123456", "name": "bar"}, rules).Errors()
+	if len(errs) != 1 || errs[0].Rule != "max" {
+		t.Errorf("expected the string rules after a Rule to run, got %v", errs)
+	}
+
+	v := New(map[string]interface{}{"code": "Y"}, rules, WithMessages(map[string]string{"code.prefix": "Bad :attribute."}))
+	if v.GetMessage() != "Bad code." {
+		t.Errorf("expected the custom message, got %q", v.GetMessage())
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("expected ErrInvalidRule, got %v", err)
+		}
+	}()
+	New(map[string]interface{}{}, map[string]interface{}{"foo": []interface{}{"required", 1}})
+}
+
+type contextKey string
+
+type confirmedRule struct{}
+
+func (confirmedRule) Validate(ctx *Context) error {
+	parent, _ := ctx.Parent.(map[string]interface{})
+	if ctx.Value == parent["password"] {
+		return nil
+	}
+
+	return &Failure{
+		Message: "The :attribute does not match (" + ctx.Locale + ", " + ctx.Context.Value(contextKey("request")).(string) + ").",
+		Meta:    map[string]interface{}{"other": "password", "user": ctx.Get("user.name")},
+	}
+}
+
+func (confirmedRule) Name() string {
+	return "confirmed"
+}
+
+func TestContextRules(t *testing.T) {
+	rules := map[string]interface{}{
+		"user.confirmation": []interface{}{"required", confirmedRule{}},
+		"user.name": func(ctx *Context) error {
+			if len(ctx.Parameters) > 0 {
+				return errors.New("unexpected parameters")
+			}
+			return nil
+		},
+	}
+	ctx := context.WithValue(context.Background(), contextKey("request"), "r1")
+
+	data := map[string]interface{}{
+		"user": map[string]interface{}{"name": "foo", "password": "secret", "confirmation": "secret"},
+	}
+	if err := New(data, rules, WithContext(ctx)).Validate(); err != nil {
+		t.Errorf("expected valid data, got %v", err)
+	}
+
+	data["user"].(map[string]interface{})["confirmation"] = "other"
+	errs := New(data, rules, WithContext(ctx), WithLocale("en")).Errors()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if e := errs[0]; e.Rule != "confirmed" || e.Message != "The user.confirmation does not match (en, r1)." || e.Meta["user"] != "foo" {
+		t.Errorf("unexpected error %+v", e)
+	}
+}
+
+type presentRule struct{}
+
+func (presentRule) Validate(ctx *Context) error {
+	parent, _ := ctx.Parent.(map[string]interface{})
+	if _, ok := parent[ctx.Attribute]; ok {
+		return nil
+	}
+
+	return &Failure{Message: "The :attribute field must be present."}
+}
+
+func (presentRule) Name() string {
+	return "present"
+}
+
+func (presentRule) Implicit() bool {
+	return true
+}
+
+func TestImplicitRules(t *testing.T) {
+	calls := 0
+	notImplicit := func(attribute string, value interface{}) bool {
+		calls++
+		return false
+	}
+	requiredWithFoo := Implicit(func(ctx *Context) error {
+		if ctx.Get("foo") != nil && ctx.Value == nil {
+			return errors.New("The :attribute field is required with foo.")
+		}
+		return nil
+	})
+
+	rules := map[string]interface{}{
+		"bar": []interface{}{requiredWithFoo, notImplicit},
+		"baz": presentRule{},
+	}
+
+	errs := New(map[string]interface{}{"foo": 1, "baz": nil}, rules).Errors()
+	if len(errs) != 1 || errs[0].Message != "The bar field is required with foo." {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	if calls != 0 {
+		t.Errorf("expected a rule that is not implicit to be skipped for a missing value")
+	}
+
+	errs = New(map[string]interface{}{}, rules).Errors()
+	if len(errs) != 1 || errs[0].Rule != "present" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
@@ -0,0 +1,191 @@
+// Package rules builds validation rules with typed methods instead of rule
+// strings, so that a misspelt rule or a parameter of the wrong type is a
+// compile error:
+//
+//	validation.New(data, map[string]interface{}{
+//		"name": rules.String().Required().Max(20),
+//		"age":  rules.Number[int]().Required().Between(18, 120),
+//	})
+//
+// A builder is a value: each method returns a new builder and leaves the
+// receiver unchanged, so a builder can be shared as a base for other rules.
+package rules
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ragopkg/validation"
+)
+
+// Numeric is the constraint of the types accepted by Number.
+type Numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// list is the rule list shared by the builders.
+type list []string
+
+func (l list) with(rules ...string) list {
+	return append(append(list{}, l...), rules...)
+}
+
+// StringRules builds the rules of a string attribute.
+type StringRules struct {
+	rules list
+}
+
+// String starts the rules of a string attribute.
+func String() StringRules {
+	return StringRules{list{"string"}}
+}
+
+// Rules returns the rule strings.
+func (r StringRules) Rules() []string {
+	return r.rules.with()
+}
+
+// Required requires a non blank value.
+func (r StringRules) Required() StringRules {
+	return StringRules{r.rules.with("required")}
+}
+
+// Alpha requires letters only.
+func (r StringRules) Alpha() StringRules {
+	return StringRules{r.rules.with("alpha")}
+}
+
+// AlphaNum requires letters and digits only.
+func (r StringRules) AlphaNum() StringRules {
+	return StringRules{r.rules.with("alpha_num")}
+}
+
+// Num requires digits only.
+func (r StringRules) Num() StringRules {
+	return StringRules{r.rules.with("num")}
+}
+
+// Email requires an email address.
+func (r StringRules) Email() StringRules {
+	return StringRules{r.rules.with("email")}
+}
+
+// Regex requires the value to match pattern.
+func (r StringRules) Regex(pattern string) StringRules {
+	return StringRules{r.rules.with("regex:" + pattern)}
+}
+
+// Min requires at least n characters.
+func (r StringRules) Min(n int) StringRules {
+	return StringRules{r.rules.with("min:" + strconv.Itoa(n))}
+}
+
+// Max requires at most n characters.
+func (r StringRules) Max(n int) StringRules {
+	return StringRules{r.rules.with("max:" + strconv.Itoa(n))}
+}
+
+// Size requires exactly n characters.
+func (r StringRules) Size(n int) StringRules {
+	return StringRules{r.rules.with("size:" + strconv.Itoa(n))}
+}
+
+// Between requires between min and max characters.
+func (r StringRules) Between(min, max int) StringRules {
+	return StringRules{r.rules.with("between:" + strconv.Itoa(min) + "," + strconv.Itoa(max))}
+}
+
+// In requires one of values.
+func (r StringRules) In(values ...string) StringRules {
+	return StringRules{r.rules.with(validation.In(values))}
+}
+
+// NotIn rejects values.
+func (r StringRules) NotIn(values ...string) StringRules {
+	return StringRules{r.rules.with(validation.NotIn(values))}
+}
+
+// NumberRules builds the rules of a numeric attribute of type T.
+type NumberRules[T Numeric] struct {
+	rules list
+}
+
+// Number starts the rules of a numeric attribute: an integer for the integer
+// types of T, a float otherwise.
+func Number[T Numeric]() NumberRules[T] {
+	if isInteger[T]() {
+		return NumberRules[T]{list{"integer"}}
+	}
+	return NumberRules[T]{list{"float"}}
+}
+
+func isInteger[T Numeric]() bool {
+	var one T = 1
+	return one/2 == 0
+}
+
+func format[T Numeric](n T) string {
+	return fmt.Sprint(n)
+}
+
+// Rules returns the rule strings.
+func (r NumberRules[T]) Rules() []string {
+	return r.rules.with()
+}
+
+// Required requires a value.
+func (r NumberRules[T]) Required() NumberRules[T] {
+	return NumberRules[T]{r.rules.with("required")}
+}
+
+// Min requires a value of at least n.
+func (r NumberRules[T]) Min(n T) NumberRules[T] {
+	return NumberRules[T]{r.rules.with("min:" + format(n))}
+}
+
+// Max requires a value of at most n.
+func (r NumberRules[T]) Max(n T) NumberRules[T] {
+	return NumberRules[T]{r.rules.with("max:" + format(n))}
+}
+
+// Size requires a value of n.
+func (r NumberRules[T]) Size(n T) NumberRules[T] {
+	return NumberRules[T]{r.rules.with("size:" + format(n))}
+}
+
+// Between requires a value between min and max.
+func (r NumberRules[T]) Between(min, max T) NumberRules[T] {
+	return NumberRules[T]{r.rules.with("between:" + format(min) + "," + format(max))}
+}
+
+// In requires one of values.
+func (r NumberRules[T]) In(values ...T) NumberRules[T] {
+	return NumberRules[T]{r.rules.with(validation.In(values))}
+}
+
+// NotIn rejects values.
+func (r NumberRules[T]) NotIn(values ...T) NumberRules[T] {
+	return NumberRules[T]{r.rules.with(validation.NotIn(values))}
+}
+
+// BoolRules builds the rules of a boolean attribute.
+type BoolRules struct {
+	rules list
+}
+
+// Bool starts the rules of a boolean attribute.
+func Bool() BoolRules {
+	return BoolRules{list{"bool"}}
+}
+
+// Rules returns the rule strings.
+func (r BoolRules) Rules() []string {
+	return r.rules.with()
+}
+
+// Required requires a value.
+func (r BoolRules) Required() BoolRules {
+	return BoolRules{r.rules.with("required")}
+}
@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ragopkg/validation"
+)
+
+func TestRules(t *testing.T) {
+	base := String().Required()
+
+	tests := map[string]struct {
+		rules    validation.RuleLister
+		expected []string
+	}{
+		"string":  {base.Max(5), []string{"string", "required", "max:5"}},
+		"base":    {base, []string{"string", "required"}},
+		"in":      {String().In("a,b", "c"), []string{"string", `in:a\,b,c`}},
+		"int":     {Number[int]().Between(1, 3), []string{"integer", "between:1,3"}},
+		"uint8":   {Number[uint8]().Min(1), []string{"integer", "min:1"}},
+		"float":   {Number[float64]().Required().Max(2.5), []string{"float", "required", "max:2.5"}},
+		"int-in":  {Number[int]().In(1, 2), []string{"integer", "in:1,2"}},
+		"boolean": {Bool().Required(), []string{"bool", "required"}},
+	}
+
+	for name, tt := range tests {
+		if rules := tt.rules.Rules(); !reflect.DeepEqual(rules, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", name, tt.expected, rules)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	rules := map[string]interface{}{
+		"name": String().Required().Max(5),
+		"age":  Number[int]().Required().Between(18, 120),
+		"role": String().In("admin", "user, guest"),
+	}
+
+	if err := validation.New(map[string]interface{}{"name": "foo", "age": 20, "role": "user, guest"}, rules).Validate(); err != nil {
+		t.Errorf("expected valid data, got %v", err)
+	}
+
+	errs := validation.New(map[string]interface{}{"name": "foobar", "age": 20.5}, rules).Errors()
+	if len(errs) != 2 || errs[0].Rule != "integer" || errs[1].Rule != "max" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestValidateNumberKinds(t *testing.T) {
+	rules := map[string]interface{}{
+		"id":    Number[int64]().Required().Min(1),
+		"level": Number[uint8]().Between(1, 5),
+		"ratio": Number[float32]().Max(1),
+	}
+
+	valid := map[string]interface{}{"id": int64(42), "level": uint8(3), "ratio": float32(0.5)}
+	if err := validation.New(valid, rules).Validate(); err != nil {
+		t.Errorf("expected valid data, got %v", err)
+	}
+
+	invalid := map[string]interface{}{"id": int64(0), "level": uint8(9), "ratio": float32(1.5)}
+	errs := validation.New(invalid, rules).Errors()
+	if len(errs) != 3 || errs[0].Rule != "min" || errs[1].Rule != "between" || errs[2].Rule != "max" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	if errs[0].Message != "The id must be at least 1." {
+		t.Errorf("expected the numeric message, got %q", errs[0].Message)
+	}
+}
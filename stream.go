@@ -0,0 +1,276 @@
+package validation
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Formats of a record stream.
+const (
+	FormatJSON   = "json"   // an object or an array of objects
+	FormatNDJSON = "ndjson" // one object per line
+	FormatCSV    = "csv"    // a header row naming the attributes, then one record per row
+)
+
+// StreamOptions configures RuleSet.Stream.
+type StreamOptions struct {
+	// Format is the format of the stream, FormatNDJSON by default.
+	Format string
+
+	// MaxFailures stops the stream after that many invalid records. Zero
+	// means no limit.
+	MaxFailures int
+
+	// Columns sets the types of CSV columns: ColumnString, ColumnInteger,
+	// ColumnNumber or ColumnBoolean. A column not listed has the type implied
+	// by the rules of its attribute (integer, float or bool), and is a string
+	// otherwise.
+	Columns map[string]string
+}
+
+// CSV column types, see StreamOptions.Columns.
+const (
+	ColumnString  = "string"
+	ColumnInteger = "integer"
+	ColumnNumber  = "number"
+	ColumnBoolean = "boolean"
+)
+
+// StreamResult is the result of one record of a stream.
+type StreamResult struct {
+	Record int // 1-based number of the record in the stream
+	Data   map[string]interface{}
+	Errors ValidationErrors // nil when the record is valid
+}
+
+// StreamStats summarises a stream.
+type StreamStats struct {
+	Records int
+	Failed  int
+	Rules   map[string]int // number of failures per rule name
+	Stopped bool           // the stream was stopped after MaxFailures invalid records
+}
+
+// Stream validates the records read from r one at a time, without loading
+// the whole input, and calls fn with the result of each record. Records are
+// decoded as by encoding/json. Empty CSV cells are left out of the record and
+// the other cells are converted to the type of their column, see
+// StreamOptions.Columns; a cell that is not valid for its type is kept as a
+// string, so that the rules of the column report it.
+//
+// Stream stops at the first error returned by fn, reading r, or caused by the
+// rules, and returns it along with the statistics of the records validated
+// so far.
+func (rs *RuleSet) Stream(r io.Reader, opts StreamOptions, fn func(StreamResult) error) (*StreamStats, error) {
+	stats := &StreamStats{Rules: map[string]int{}}
+
+	errStop := errors.New("stop")
+	columnType := func(attribute string) string {
+		if typ, ok := opts.Columns[attribute]; ok {
+			return typ
+		}
+		for _, rule := range rs.rules[attribute] {
+			if typ, ok := ruleSchemaTypes[rule.name]; ok {
+				return typ
+			}
+		}
+		return ColumnString
+	}
+
+	err := readRecords(r, opts.Format, columnType, func(n int, data map[string]interface{}) error {
+		err := rs.New(data).Validate()
+
+		var errs ValidationErrors
+		if err != nil && !errors.As(err, &errs) {
+			return err
+		}
+
+		stats.Records++
+		if len(errs) > 0 {
+			stats.Failed++
+			for _, e := range errs {
+				stats.Rules[e.Rule]++
+			}
+		}
+
+		if err := fn(StreamResult{n, data, errs}); err != nil {
+			return err
+		}
+
+		if opts.MaxFailures > 0 && stats.Failed >= opts.MaxFailures {
+			stats.Stopped = true
+			return errStop
+		}
+		return nil
+	})
+	if err == errStop {
+		err = nil
+	}
+
+	return stats, err
+}
+
+// readRecords calls fn with each record of r and its 1-based number. An empty
+// input has no records. columnType returns the type of a CSV column.
+func readRecords(r io.Reader, format string, columnType func(string) string, fn func(int, map[string]interface{}) error) error {
+	switch format {
+	case FormatJSON:
+		return readJSON(r, fn)
+	case FormatNDJSON, "":
+		return readNDJSON(r, fn)
+	case FormatCSV:
+		return readCSV(r, columnType, fn)
+	default:
+		return fmt.Errorf("validation: unknown stream format %q", format)
+	}
+}
+
+// readJSON reads an object, or the objects of an array one at a time, and
+// rejects data after it.
+func readJSON(r io.Reader, fn func(int, map[string]interface{}) error) error {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(br)
+	switch first {
+	case '{':
+		var record map[string]interface{}
+		if err := dec.Decode(&record); err != nil {
+			return fmt.Errorf("record 1: %w", err)
+		}
+		if err := fn(1, record); err != nil {
+			return err
+		}
+		return expectEOF(dec)
+	case '[':
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		for n := 1; dec.More(); n++ {
+			var record map[string]interface{}
+			if err := dec.Decode(&record); err != nil {
+				return fmt.Errorf("record %d: %w", n, err)
+			}
+			if err := fn(n, record); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		return expectEOF(dec)
+	default:
+		return errors.New("validation: an object or an array of objects is expected")
+	}
+}
+
+// peekNonSpace skips white space and returns the next byte without consuming it.
+// expectEOF checks that nothing but white space follows the top-level JSON
+// value read by dec.
+func expectEOF(dec *json.Decoder) error {
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("validation: unexpected data after the top-level JSON value")
+	}
+
+	return nil
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+func readNDJSON(r io.Reader, fn func(int, map[string]interface{}) error) error {
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var record map[string]interface{}
+		if err := dec.Decode(&record); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("record %d: %w", n, err)
+		}
+		if err := fn(n, record); err != nil {
+			return err
+		}
+	}
+}
+
+func readCSV(r io.Reader, columnType func(string) string, fn func(int, map[string]interface{}) error) error {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	header = append([]string{}, header...)
+
+	types := make([]string, len(header))
+	for i, attribute := range header {
+		types[i] = columnType(attribute)
+		switch types[i] {
+		case ColumnString, ColumnInteger, ColumnNumber, ColumnBoolean:
+		default:
+			return fmt.Errorf("validation: unknown type %q of column %s", types[i], attribute)
+		}
+	}
+
+	for n := 1; ; n++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("record %d: %w", n, err)
+		}
+
+		record := make(map[string]interface{}, len(header))
+		for i, attribute := range header {
+			if row[i] != "" {
+				record[attribute] = convertCell(row[i], types[i])
+			}
+		}
+		if err := fn(n, record); err != nil {
+			return err
+		}
+	}
+}
+
+// convertCell converts a CSV cell to a column type, keeping the string when
+// it is not valid for the type.
+func convertCell(cell, typ string) interface{} {
+	switch typ {
+	case ColumnInteger:
+		if n, err := strconv.Atoi(cell); err == nil {
+			return n
+		}
+	case ColumnNumber:
+		if f, err := strconv.ParseFloat(cell, 64); err == nil {
+			return f
+		}
+	case ColumnBoolean:
+		if b, err := strconv.ParseBool(cell); err == nil {
+			return b
+		}
+	}
+
+	return cell
+}
@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	ruleSet := NewRuleSet(map[string]interface{}{"name": "required|alpha", "age": "required|min:18"})
+
+	inputs := map[string]string{
+		FormatJSON:   `[{"name": "foo", "age": 20}, {"name": "f00", "age": 20}, {"age": 10}, {"name": "bar", "age": 30}]`,
+		FormatNDJSON: "{\"name\": \"foo\", \"age\": 20}\n{\"name\": \"f00\", \"age\": 20}\n{\"age\": 10}\n{\"name\": \"bar\", \"age\": 30}\n",
+	}
+	for format, input := range inputs {
+		failed := []int{}
+		stats, err := ruleSet.Stream(strings.NewReader(input), StreamOptions{Format: format}, func(result StreamResult) error {
+			if result.Errors != nil {
+				failed = append(failed, result.Record)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if len(failed) != 2 || failed[0] != 2 || failed[1] != 3 {
+			t.Errorf("%s: unexpected failed records %v", format, failed)
+		}
+		if stats.Records != 4 || stats.Failed != 2 || stats.Rules["alpha"] != 1 || stats.Rules["required"] != 1 || stats.Rules["min"] != 1 {
+			t.Errorf("%s: unexpected stats %+v", format, stats)
+		}
+	}
+
+	stats, err := ruleSet.Stream(strings.NewReader(`{"name": "foo", "age": 20}`), StreamOptions{Format: FormatJSON}, func(StreamResult) error { return nil })
+	if err != nil || stats.Records != 1 || stats.Failed != 0 {
+		t.Errorf("unexpected result for a single object: %+v, %v", stats, err)
+	}
+}
+
+func TestStreamEmptyAndTrailing(t *testing.T) {
+	ruleSet := NewRuleSet(map[string]interface{}{"name": "required"})
+	noop := func(StreamResult) error { return nil }
+
+	for _, format := range []string{FormatJSON, FormatNDJSON, FormatCSV} {
+		stats, err := ruleSet.Stream(strings.NewReader(""), StreamOptions{Format: format}, noop)
+		if err != nil || stats.Records != 0 {
+			t.Errorf("%s: expected no records for an empty input, got %+v, %v", format, stats, err)
+		}
+	}
+
+	for _, input := range []string{`{"name": "foo"} {"name": "bar"}`, `[{"name": "foo"}] x`} {
+		if _, err := ruleSet.Stream(strings.NewReader(input), StreamOptions{Format: FormatJSON}, noop); err == nil {
+			t.Errorf("%s: expected an error for trailing data", input)
+		}
+	}
+	if _, err := ruleSet.Stream(strings.NewReader("[{\"name\": \"foo\"}]\n"), StreamOptions{Format: FormatJSON}, noop); err != nil {
+		t.Errorf("expected trailing white space to be accepted, got %v", err)
+	}
+}
+
+func TestStreamCSV(t *testing.T) {
+	ruleSet := NewRuleSet(map[string]interface{}{"name": "required|alpha", "age": "required|num"})
+	input := "name,age\nfoo,20\nf00,20\nfoo,\nbar,x\nbaz,30\n"
+
+	stats, err := ruleSet.Stream(strings.NewReader(input), StreamOptions{Format: FormatCSV, MaxFailures: 2}, func(StreamResult) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Stopped || stats.Records != 3 || stats.Failed != 2 {
+		t.Errorf("expected the stream to stop after 2 failures, got %+v", stats)
+	}
+
+	errStop := errors.New("stop")
+	_, err = ruleSet.Stream(strings.NewReader(input), StreamOptions{Format: FormatCSV}, func(StreamResult) error { return errStop })
+	if err != errStop {
+		t.Errorf("expected the callback error, got %v", err)
+	}
+}
+
+func TestStreamCSVTypes(t *testing.T) {
+	ruleSet := NewRuleSet(map[string]interface{}{
+		"email":  "email",
+		"age":    "integer|min:18",
+		"score":  "float|between:0,10",
+		"active": "bool",
+		"zip":    "size:5",
+		"rank":   "max:3",
+	})
+	input := "email,age,score,active,zip,rank,note\n,20,7.5,true,01234,2,\nfoo,x,11,yes,1234,4,n\n"
+
+	records := []StreamResult{}
+	_, err := ruleSet.Stream(strings.NewReader(input), StreamOptions{Format: FormatCSV, Columns: map[string]string{"rank": ColumnInteger}}, func(result StreamResult) error {
+		records = append(records, result)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{"age": 20, "score": 7.5, "active": true, "zip": "01234", "rank": 2}
+	if !reflect.DeepEqual(records[0].Data, expected) || records[0].Errors != nil {
+		t.Errorf("unexpected first record %v, %v", records[0].Data, records[0].Errors)
+	}
+
+	failed := map[string]bool{}
+	for _, e := range records[1].Errors {
+		failed[e.Field] = true
+	}
+	if len(failed) != 6 || failed["note"] {
+		t.Errorf("unexpected errors for the second record %v", records[1].Errors)
+	}
+
+	_, err = ruleSet.Stream(strings.NewReader(input), StreamOptions{Format: FormatCSV, Columns: map[string]string{"age": "date"}}, func(StreamResult) error { return nil })
+	if err == nil {
+		t.Errorf("expected an error for an unknown column type")
+	}
+}
@@ -0,0 +1,266 @@
+package validation
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	// Embed the time zone database so that the timezone rule does not depend
+	// on the zoneinfo files of the host.
+	_ "time/tzdata"
+)
+
+var (
+	//go:embed data/countries.tsv
+	countryData []byte
+
+	//go:embed data/currencies.txt
+	currencyData []byte
+
+	//go:embed data/languages.txt
+	languageData []byte
+
+	//go:embed data/scripts.txt
+	scriptData []byte
+)
+
+// codeSets are the code tables, parsed once from the embedded data.
+var codeSets struct {
+	once       sync.Once
+	alpha2     map[string]bool
+	alpha3     map[string]bool
+	currencies map[string]bool
+	languages  map[string]bool
+	scripts    map[string]bool
+}
+
+func loadCodeSets() {
+	codeSets.once.Do(func() {
+		codeSets.alpha2 = readCodes(countryData, 0, false)
+		codeSets.alpha3 = readCodes(countryData, 1, false)
+		codeSets.currencies = readCodes(currencyData, 0, false)
+		codeSets.languages = readCodes(languageData, 0, true)
+		codeSets.scripts = readCodes(scriptData, 0, true)
+	})
+}
+
+// readCodes returns the codes in a column of tab separated data, lower cased
+// when lower is set. Blank lines and lines starting with # are ignored.
+func readCodes(data []byte, column int, lower bool) map[string]bool {
+	codes := map[string]bool{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		code := strings.Split(line, "\t")[column]
+		if lower {
+			code = strings.ToLower(code)
+		}
+		codes[code] = true
+	}
+
+	return codes
+}
+
+// checkCountryParameters checks the optional code length parameter of
+// country.
+func checkCountryParameters(parameters []string) error {
+	if len(parameters) > 1 {
+		return fmt.Errorf("%w: rule country takes at most 1 parameter", ErrInvalidParameter)
+	}
+	if len(parameters) == 1 && parameters[0] != "alpha2" && parameters[0] != "alpha3" {
+		return fmt.Errorf("%w: rule country requires alpha2 or alpha3, got %q", ErrInvalidParameter, parameters[0])
+	}
+
+	return nil
+}
+
+// validateCountry checks an upper case ISO 3166-1 country code, alpha-2 or
+// alpha-3, or only the one given as parameter ("country:alpha2",
+// "country:alpha3").
+func validateCountry(ctx *Context) bool {
+	requireParameters(checkCountryParameters, ctx.Parameters)
+	loadCodeSets()
+
+	sets := []map[string]bool{codeSets.alpha2, codeSets.alpha3}
+	if len(ctx.Parameters) > 0 && ctx.Parameters[0] == "alpha2" {
+		sets = sets[:1]
+	} else if len(ctx.Parameters) > 0 {
+		sets = sets[1:]
+	}
+
+	code, ok := ctx.Value.(string)
+	if !ok {
+		return false
+	}
+	for _, set := range sets {
+		if set[code] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateCurrency checks an upper case ISO 4217 currency code.
+func validateCurrency(ctx *Context) bool {
+	loadCodeSets()
+
+	code, ok := ctx.Value.(string)
+
+	return ok && codeSets.currencies[code]
+}
+
+// validateLanguage checks a BCP 47 language tag, such as "en", "en-GB" or
+// "zh-Hant-TW", see isLanguageTag.
+func validateLanguage(ctx *Context) bool {
+	tag, ok := ctx.Value.(string)
+
+	return ok && isLanguageTag(tag)
+}
+
+// isLanguageTag checks the syntax of a BCP 47 language tag and that its
+// language, script and region subtags are ISO 639, ISO 15924 and ISO 3166-1
+// (or UN M.49) codes. Tags are case insensitive. Grandfathered tags and
+// language subtags of 4 to 8 letters are not accepted.
+func isLanguageTag(tag string) bool {
+	loadCodeSets()
+
+	subtags := strings.Split(strings.ToLower(tag), "-")
+	if subtags[0] == "x" {
+		return isPrivateUse(subtags[1:])
+	}
+
+	// language, with up to three extended language subtags
+	if !isAlpha(subtags[0], 2, 3) || !(codeSets.languages[subtags[0]] || isReservedLanguage(subtags[0])) {
+		return false
+	}
+	i := 1
+	for n := 0; n < 3 && i < len(subtags) && isAlpha(subtags[i], 3, 3); n++ {
+		if !codeSets.languages[subtags[i]] {
+			return false
+		}
+		i++
+	}
+
+	if i < len(subtags) && isAlpha(subtags[i], 4, 4) {
+		if !codeSets.scripts[subtags[i]] {
+			return false
+		}
+		i++
+	}
+
+	if i < len(subtags) && (isAlpha(subtags[i], 2, 2) || isDigitsN(subtags[i], 3)) {
+		if isAlpha(subtags[i], 2, 2) && !codeSets.alpha2[strings.ToUpper(subtags[i])] {
+			return false
+		}
+		i++
+	}
+
+	for i < len(subtags) && isVariant(subtags[i]) {
+		i++
+	}
+
+	for i < len(subtags) && len(subtags[i]) == 1 && subtags[i] != "x" {
+		j := i + 1
+		for j < len(subtags) && isAlnum(subtags[j], 2, 8) {
+			j++
+		}
+		if j == i+1 {
+			return false
+		}
+		i = j
+	}
+
+	if i < len(subtags) && subtags[i] == "x" {
+		return isPrivateUse(subtags[i+1:])
+	}
+
+	return i == len(subtags)
+}
+
+// isReservedLanguage reports whether a language subtag is in the ISO 639-2
+// range qaa-qtz reserved for local use.
+func isReservedLanguage(s string) bool {
+	return len(s) == 3 && s >= "qaa" && s <= "qtz"
+}
+
+func isPrivateUse(subtags []string) bool {
+	if len(subtags) == 0 {
+		return false
+	}
+	for _, subtag := range subtags {
+		if !isAlnum(subtag, 1, 8) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isVariant(s string) bool {
+	return isAlnum(s, 5, 8) || (len(s) == 4 && s[0] >= '0' && s[0] <= '9' && isAlnum(s, 4, 4))
+}
+
+func isAlpha(s string, min, max int) bool {
+	if len(s) < min || len(s) > max {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isAlnum(s string, min, max int) bool {
+	if len(s) < min || len(s) > max {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if (s[i] < 'a' || s[i] > 'z') && (s[i] < '0' || s[i] > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isDigitsN(s string, n int) bool {
+	return len(s) == n && isDigits(s)
+}
+
+// timezones caches the names loaded by time.LoadLocation, which reads the
+// zoneinfo database on every call. Invalid names are not cached so that
+// arbitrary values do not grow the cache.
+var timezones sync.Map
+
+// validateTimezone checks an IANA time zone name, such as "Europe/Paris" or
+// "UTC", as loaded by time.LoadLocation, which falls back to the embedded
+// database on hosts without zoneinfo files.
+func validateTimezone(ctx *Context) bool {
+	name, ok := ctx.Value.(string)
+	if !ok || name == "" || name == "Local" {
+		return false
+	}
+
+	if _, ok := timezones.Load(name); ok {
+		return true
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return false
+	}
+	timezones.Store(name, true)
+
+	return true
+}
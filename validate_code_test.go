@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCodeRules(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		rule  string
+		pass  bool
+	}{
+		{"US", "country", true},
+		{"DEU", "country", true},
+		{"GB", "country:alpha2", true},
+		{"GBR", "country:alpha2", false},
+		{"GBR", "country:alpha3", true},
+		{"us", "country", false},
+		{"XX", "country", false},
+		{1, "country", false},
+
+		{"EUR", "currency", true},
+		{"JPY", "currency", true},
+		{"eur", "currency", false},
+		{"ABC", "currency", false},
+
+		{"en", "language", true},
+		{"en-GB", "language", true},
+		{"EN-gb", "language", true},
+		{"zh-Hant-TW", "language", true},
+		{"es-419", "language", true},
+		{"sr-Latn-RS", "language", true},
+		{"de-DE-1996", "language", true},
+		{"en-US-u-ca-gregory", "language", true},
+		{"en-x-custom", "language", true},
+		{"x-whatever", "language", true},
+		{"yue", "language", true},
+		{"zh-yue-HK", "language", true},
+		{"qaa", "language", true},
+		{"", "language", false},
+		{"english", "language", false},
+		{"xx", "language", false},
+		{"en-Abcd", "language", false},
+		{"en-ZZ", "language", false},
+		{"en-", "language", false},
+		{"en-u", "language", false},
+		{"en-x", "language", false},
+		{"en_GB", "language", false},
+
+		{"Europe/Paris", "timezone", true},
+		{"America/Argentina/Buenos_Aires", "timezone", true},
+		{"UTC", "timezone", true},
+		{"Local", "timezone", false},
+		{"Mars/Olympus_Mons", "timezone", false},
+		{"../etc/passwd", "timezone", false},
+	}
+
+	for _, tt := range tests {
+		validator := New(map[string]interface{}{"foo": tt.value}, map[string]interface{}{"foo": tt.rule})
+		if validator.Passes() != tt.pass {
+			t.Errorf("%s: expected %v for %v", tt.rule, tt.pass, tt.value)
+		}
+	}
+
+	if _, ok := timezones.Load("Europe/Paris"); !ok {
+		t.Errorf("expected a loaded time zone to be cached")
+	}
+	if _, ok := timezones.Load("Mars/Olympus_Mons"); ok {
+		t.Errorf("expected an invalid time zone not to be cached")
+	}
+
+	err := New(map[string]interface{}{"foo": "US"}, map[string]interface{}{"foo": "country:numeric"}).Validate()
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// toFloat converts a number of any integer or float kind, or a numeric string
+// such as a json.Number, to a float64. NaN and infinities are rejected.
+func toFloat(value interface{}) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+
+	var f float64
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f = float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		f = rv.Float()
+	case reflect.String:
+		var err error
+		if f, err = strconv.ParseFloat(strings.TrimSpace(rv.String()), 64); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	return f, !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+func isLatitude(value interface{}) bool {
+	lat, ok := toFloat(value)
+	return ok && lat >= -90 && lat <= 90
+}
+
+func isLongitude(value interface{}) bool {
+	lng, ok := toFloat(value)
+	return ok && lng >= -180 && lng <= 180
+}
+
+// toLatLng converts a coordinate pair to its latitude and longitude. The pair
+// is a "lat,lng" string, a slice or array of two numbers, or a map with lat
+// and lng keys.
+func toLatLng(value interface{}) (float64, float64, bool) {
+	var lat, lng interface{}
+	if s, ok := value.(string); ok {
+		parts := strings.Split(s, ",")
+		if len(parts) != 2 {
+			return 0, 0, false
+		}
+		lat, lng = parts[0], parts[1]
+	} else if m, ok := value.(map[string]interface{}); ok {
+		if len(m) != 2 {
+			return 0, 0, false
+		}
+		lat, lng = m["lat"], m["lng"]
+	} else {
+		rv := reflect.ValueOf(value)
+		if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() != 2 {
+			return 0, 0, false
+		}
+		lat, lng = rv.Index(0).Interface(), rv.Index(1).Interface()
+	}
+
+	if !isLatitude(lat) || !isLongitude(lng) {
+		return 0, 0, false
+	}
+	latitude, _ := toFloat(lat)
+	longitude, _ := toFloat(lng)
+
+	return latitude, longitude, true
+}
+
+func validateLatitude(ctx *Context) bool {
+	return isLatitude(ctx.Value)
+}
+
+func validateLongitude(ctx *Context) bool {
+	return isLongitude(ctx.Value)
+}
+
+func validateLatLng(ctx *Context) bool {
+	_, _, ok := toLatLng(ctx.Value)
+	return ok
+}
+
+// checkWithinBboxParameters checks the four bounds of within_bbox.
+func checkWithinBboxParameters(parameters []string) error {
+	if len(parameters) != 4 {
+		return fmt.Errorf("%w: rule within_bbox requires 4 parameters", ErrInvalidParameter)
+	}
+	for _, parameter := range parameters {
+		if _, err := strconv.ParseFloat(parameter, 64); err != nil {
+			return fmt.Errorf("%w: rule within_bbox requires a float string, got %q", ErrInvalidParameter, parameter)
+		}
+	}
+
+	return nil
+}
+
+// validateWithinBbox checks that a coordinate pair, as accepted by lat_lng,
+// is within the box "within_bbox:minLat,minLng,maxLat,maxLng", bounds
+// included. A box with minLng greater than maxLng crosses the antimeridian.
+func validateWithinBbox(ctx *Context) bool {
+	requireParameters(checkWithinBboxParameters, ctx.Parameters)
+
+	minLat := stringTofloat64("within_bbox", ctx.Parameters[0])
+	minLng := stringTofloat64("within_bbox", ctx.Parameters[1])
+	maxLat := stringTofloat64("within_bbox", ctx.Parameters[2])
+	maxLng := stringTofloat64("within_bbox", ctx.Parameters[3])
+
+	lat, lng, ok := toLatLng(ctx.Value)
+	if !ok || lat < minLat || lat > maxLat {
+		return false
+	}
+	if minLng <= maxLng {
+		return lng >= minLng && lng <= maxLng
+	}
+
+	return lng >= minLng || lng <= maxLng
+}
+
+// checkGeojsonParameters checks the geometry types given to geojson.
+func checkGeojsonParameters(parameters []string) error {
+	for _, parameter := range parameters {
+		if !strings.EqualFold(parameter, "Point") && !strings.EqualFold(parameter, "Polygon") {
+			return fmt.Errorf("%w: rule geojson requires Point or Polygon, got %q", ErrInvalidParameter, parameter)
+		}
+	}
+
+	return nil
+}
+
+// validateGeojson checks a GeoJSON Point or Polygon geometry, given as a map
+// or a JSON string, or only the types given as parameters ("geojson:Point").
+// Positions are [longitude, latitude] with an optional altitude, and the
+// rings of a polygon are closed and have at least four positions.
+func validateGeojson(ctx *Context) bool {
+	requireParameters(checkGeojsonParameters, ctx.Parameters)
+
+	geometry := ctx.Value
+	if s, ok := geometry.(string); ok {
+		if err := json.Unmarshal([]byte(s), &geometry); err != nil {
+			return false
+		}
+	}
+
+	m, ok := geometry.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	typ, _ := m["type"].(string)
+	if len(ctx.Parameters) > 0 && !inList(typ, ctx.Parameters, true) {
+		return false
+	}
+
+	switch typ {
+	case "Point":
+		return isPosition(m["coordinates"])
+	case "Polygon":
+		rings, ok := m["coordinates"].([]interface{})
+		if !ok || len(rings) == 0 {
+			return false
+		}
+		for _, ring := range rings {
+			if !isLinearRing(ring) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// isPosition checks a GeoJSON position: [longitude, latitude] or
+// [longitude, latitude, altitude].
+func isPosition(value interface{}) bool {
+	position, ok := value.([]interface{})
+	if !ok || len(position) < 2 || len(position) > 3 {
+		return false
+	}
+	for _, n := range position {
+		if _, ok := n.(string); ok {
+			return false
+		}
+		if _, ok := toFloat(n); !ok {
+			return false
+		}
+	}
+
+	return isLongitude(position[0]) && isLatitude(position[1])
+}
+
+// isLinearRing checks a closed ring of at least four positions.
+func isLinearRing(value interface{}) bool {
+	ring, ok := value.([]interface{})
+	if !ok || len(ring) < 4 {
+		return false
+	}
+	for _, position := range ring {
+		if !isPosition(position) {
+			return false
+		}
+	}
+
+	first, last := ring[0].([]interface{}), ring[len(ring)-1].([]interface{})
+	if len(first) != len(last) {
+		return false
+	}
+	for i := range first {
+		a, _ := toFloat(first[i])
+		b, _ := toFloat(last[i])
+		if a != b {
+			return false
+		}
+	}
+
+	return true
+}
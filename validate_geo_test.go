@@ -0,0 +1,75 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestGeoRules(t *testing.T) {
+	square := []interface{}{
+		[]interface{}{0, 0}, []interface{}{1, 0}, []interface{}{1, 1}, []interface{}{0, 1}, []interface{}{0.0, 0.0},
+	}
+
+	tests := []struct {
+		value interface{}
+		rule  string
+		pass  bool
+	}{
+		{48.8566, "latitude", true},
+		{"-90", "latitude", true},
+		{int32(45), "latitude", true},
+		{json.Number("12.5"), "latitude", true},
+		{90.0001, "latitude", false},
+		{"north", "latitude", false},
+		{"NaN", "latitude", false},
+		{true, "latitude", false},
+
+		{-180, "longitude", true},
+		{" 2.3522 ", "longitude", true},
+		{180.5, "longitude", false},
+
+		{"48.8566,2.3522", "lat_lng", true},
+		{"48.8566, 2.3522", "lat_lng", true},
+		{[]interface{}{48.8566, "2.3522"}, "lat_lng", true},
+		{[2]float64{-33.86, 151.21}, "lat_lng", true},
+		{map[string]interface{}{"lat": 1, "lng": 2}, "lat_lng", true},
+		{"2.3522", "lat_lng", false},
+		{"91,0", "lat_lng", false},
+		{[]interface{}{1, 2, 3}, "lat_lng", false},
+		{map[string]interface{}{"lat": 1, "lon": 2}, "lat_lng", false},
+
+		{"48.8566,2.3522", "within_bbox:41,-5,51.5,10", true},
+		{"41,-5", "within_bbox:41,-5,51.5,10", true},
+		{"40.4,-3.7", "within_bbox:41,-5,51.5,10", false},
+		{"52.5,13.4", "within_bbox:41,-5,51.5,10", false},
+		{"-17.7,178.4", "within_bbox:-21,177,-12,-178", true},
+		{"-17.7,-179.5", "within_bbox:-21,177,-12,-178", true},
+		{"-17.7,170", "within_bbox:-21,177,-12,-178", false},
+
+		{map[string]interface{}{"type": "Point", "coordinates": []interface{}{2.35, 48.85}}, "geojson", true},
+		{`{"type": "Point", "coordinates": [2.35, 48.85, 35]}`, "geojson:Point", true},
+		{map[string]interface{}{"type": "Polygon", "coordinates": []interface{}{square}}, "geojson", true},
+		{map[string]interface{}{"type": "Polygon", "coordinates": []interface{}{square}}, "geojson:Point", false},
+		{map[string]interface{}{"type": "Point", "coordinates": []interface{}{48.85, 200}}, "geojson", false},
+		{map[string]interface{}{"type": "Point", "coordinates": []interface{}{"2.35", "48.85"}}, "geojson", false},
+		{map[string]interface{}{"type": "Polygon", "coordinates": []interface{}{square[:4]}}, "geojson", false},
+		{map[string]interface{}{"type": "Polygon", "coordinates": []interface{}{square[1:]}}, "geojson", false},
+		{map[string]interface{}{"type": "LineString", "coordinates": square}, "geojson", false},
+		{`{"type": "Point"`, "geojson", false},
+	}
+
+	for _, tt := range tests {
+		validator := New(map[string]interface{}{"foo": tt.value}, map[string]interface{}{"foo": tt.rule})
+		if validator.Passes() != tt.pass {
+			t.Errorf("%s: expected %v for %v", tt.rule, tt.pass, tt.value)
+		}
+	}
+
+	for _, rule := range []string{"within_bbox:1,2,3", "within_bbox:a,2,3,4", "geojson:LineString"} {
+		err := New(map[string]interface{}{"foo": "1,2"}, map[string]interface{}{"foo": rule}).Validate()
+		if !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("%s: expected ErrInvalidParameter, got %v", rule, err)
+		}
+	}
+}
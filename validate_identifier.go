@@ -0,0 +1,319 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Card brands detected by CardBrand and accepted as credit_card parameters.
+const (
+	CardAmex       = "amex"
+	CardDiners     = "diners"
+	CardDiscover   = "discover"
+	CardJCB        = "jcb"
+	CardMastercard = "mastercard"
+	CardUnionPay   = "unionpay"
+	CardVisa       = "visa"
+)
+
+// cardBrands are the brands with their number prefix ranges and lengths.
+// Ranges are compared on the leading digits of the number, as many digits as
+// the bounds have.
+var cardBrands = []struct {
+	brand   string
+	ranges  [][2]string
+	lengths []int
+}{
+	{CardAmex, [][2]string{{"34", "34"}, {"37", "37"}}, []int{15}},
+	{CardDiners, [][2]string{{"300", "305"}, {"36", "36"}, {"38", "39"}}, []int{14, 15, 16, 17, 18, 19}},
+	{CardDiscover, [][2]string{{"6011", "6011"}, {"644", "649"}, {"65", "65"}}, []int{16, 17, 18, 19}},
+	{CardJCB, [][2]string{{"3528", "3589"}}, []int{16, 17, 18, 19}},
+	{CardMastercard, [][2]string{{"51", "55"}, {"2221", "2720"}}, []int{16}},
+	{CardUnionPay, [][2]string{{"62", "62"}}, []int{16, 17, 18, 19}},
+	{CardVisa, [][2]string{{"4", "4"}}, []int{13, 16, 19}},
+}
+
+// ibanLengths are the lengths of the IBANs of the countries using them.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22,
+	"BH": 22, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22,
+	"DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18, "FO": 18, "FR": 27,
+	"GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28,
+	"IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20,
+	"LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "LY": 25, "MC": 27,
+	"MD": 24, "ME": 22, "MK": 19, "MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15,
+	"PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "SA": 24,
+	"SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+var (
+	regexpBIC          = regexp.MustCompile("^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$")
+	regexpIBANBody     = regexp.MustCompile("^[A-Z]{2}[0-9]{2}[A-Z0-9]+$")
+	identifierReplacer = strings.NewReplacer(" ", "", "-", "")
+)
+
+// identifierDigits returns the value as a string without spaces and hyphens,
+// and whether the value is a string or an integer.
+func identifierDigits(value interface{}) (string, bool) {
+	switch value.(type) {
+	case float32, float64:
+		return "", false
+	}
+
+	s, ok := toString(value)
+	if !ok {
+		return "", false
+	}
+
+	return identifierReplacer.Replace(s), true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// luhn reports whether the digits pass the Luhn checksum.
+func luhn(digits string) bool {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return sum%10 == 0
+}
+
+// gtinChecksum reports whether the digits of a GTIN (EAN-8, UPC-A, EAN-13)
+// end with a valid check digit.
+func gtinChecksum(digits string) bool {
+	sum := 0
+	for i := len(digits) - 2; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	return (10-sum%10)%10 == int(digits[len(digits)-1]-'0')
+}
+
+// CardBrand returns the brand of a card number, one of the Card constants, or
+// an empty string when the brand is unknown. It does not check the Luhn
+// checksum. Spaces and hyphens are ignored.
+func CardBrand(number string) string {
+	number = identifierReplacer.Replace(number)
+	if !isDigits(number) {
+		return ""
+	}
+
+	for _, card := range cardBrands {
+		if !containsInt(card.lengths, len(number)) {
+			continue
+		}
+		for _, r := range card.ranges {
+			n := len(r[0])
+			if len(number) >= n && number[:n] >= r[0] && number[:n] <= r[1] {
+				return card.brand
+			}
+		}
+	}
+
+	return ""
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkCreditCardParameters checks that the parameters of credit_card are
+// known brands.
+func checkCreditCardParameters(parameters []string) error {
+	for _, parameter := range parameters {
+		if !isCardBrand(parameter) {
+			return fmt.Errorf("%w: rule credit_card has an unknown brand %q", ErrInvalidParameter, parameter)
+		}
+	}
+
+	return nil
+}
+
+// validateCreditCard checks the Luhn checksum and the brand of a card number.
+// The parameters, when given, are the accepted brands.
+func validateCreditCard(ctx *Context) bool {
+	requireParameters(checkCreditCardParameters, ctx.Parameters)
+
+	number, ok := identifierDigits(ctx.Value)
+	if !ok || !isDigits(number) || len(number) < 12 || len(number) > 19 || !luhn(number) {
+		return false
+	}
+
+	brand := CardBrand(number)
+
+	return brand != "" && (len(ctx.Parameters) == 0 || inList(brand, ctx.Parameters, true))
+}
+
+func isCardBrand(brand string) bool {
+	for _, card := range cardBrands {
+		if strings.EqualFold(card.brand, brand) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateIban checks the length for the country and the mod-97 checksum of
+// an IBAN. Spaces are ignored and letters may be lower case.
+func validateIban(ctx *Context) bool {
+	iban, ok := ctx.Value.(string)
+	if !ok {
+		return false
+	}
+
+	iban = strings.ToUpper(strings.Replace(iban, " ", "", -1))
+	if !regexpIBANBody.MatchString(iban) || ibanLengths[iban[:2]] != len(iban) {
+		return false
+	}
+
+	// Move the country code and check digits to the end and compute the
+	// remainder digit by digit, letters counting as 10 to 35.
+	remainder := 0
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' {
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+
+	return remainder == 1
+}
+
+// validateBic checks the format of a BIC (SWIFT code) of 8 or 11 characters.
+func validateBic(ctx *Context) bool {
+	bic, ok := ctx.Value.(string)
+
+	return ok && regexpBIC.MatchString(bic)
+}
+
+// checkIsbnParameters checks the optional version parameter of isbn.
+func checkIsbnParameters(parameters []string) error {
+	if len(parameters) > 1 {
+		return fmt.Errorf("%w: rule isbn takes at most 1 parameter", ErrInvalidParameter)
+	}
+	if len(parameters) == 1 && parameters[0] != "10" && parameters[0] != "13" {
+		return fmt.Errorf("%w: rule isbn requires 10 or 13, got %q", ErrInvalidParameter, parameters[0])
+	}
+
+	return nil
+}
+
+// validateIsbn checks an ISBN-10 or ISBN-13, or only the one given as
+// parameter ("isbn:10", "isbn:13"). Spaces and hyphens are ignored.
+func validateIsbn(ctx *Context) bool {
+	requireParameters(checkIsbnParameters, ctx.Parameters)
+
+	version := ""
+	if len(ctx.Parameters) > 0 {
+		version = ctx.Parameters[0]
+	}
+
+	isbn, ok := identifierDigits(ctx.Value)
+	if !ok {
+		return false
+	}
+
+	switch len(isbn) {
+	case 10:
+		return version != "13" && isbn10(isbn)
+	case 13:
+		return version != "10" && isDigits(isbn) &&
+			(strings.HasPrefix(isbn, "978") || strings.HasPrefix(isbn, "979")) && gtinChecksum(isbn)
+	default:
+		return false
+	}
+}
+
+// isbn10 checks an ISBN-10, whose check digit may be X for 10.
+func isbn10(isbn string) bool {
+	if !isDigits(isbn[:9]) {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(isbn[i]-'0')
+	}
+	switch check := isbn[9]; {
+	case check == 'X' || check == 'x':
+		sum += 10
+	case check >= '0' && check <= '9':
+		sum += int(check - '0')
+	default:
+		return false
+	}
+
+	return sum%11 == 0
+}
+
+// checkEanParameters checks the optional length parameter of ean.
+func checkEanParameters(parameters []string) error {
+	if len(parameters) > 1 {
+		return fmt.Errorf("%w: rule ean takes at most 1 parameter", ErrInvalidParameter)
+	}
+	if len(parameters) == 1 && parameters[0] != "8" && parameters[0] != "13" {
+		return fmt.Errorf("%w: rule ean requires 8 or 13, got %q", ErrInvalidParameter, parameters[0])
+	}
+
+	return nil
+}
+
+// validateEan checks an EAN-8 or EAN-13, or only the one given as parameter
+// ("ean:8", "ean:13").
+func validateEan(ctx *Context) bool {
+	requireParameters(checkEanParameters, ctx.Parameters)
+
+	length := 0
+	if len(ctx.Parameters) > 0 {
+		length, _ = strconv.Atoi(ctx.Parameters[0])
+	}
+
+	ean, ok := identifierDigits(ctx.Value)
+	if !ok || !isDigits(ean) || (len(ean) != 8 && len(ean) != 13) || (length != 0 && len(ean) != length) {
+		return false
+	}
+
+	return gtinChecksum(ean)
+}
+
+// validateUpc checks a UPC-A of 12 digits.
+func validateUpc(ctx *Context) bool {
+	upc, ok := identifierDigits(ctx.Value)
+
+	return ok && len(upc) == 12 && isDigits(upc) && gtinChecksum(upc)
+}
@@ -0,0 +1,95 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIdentifierRules(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		rule  string
+		pass  bool
+	}{
+		{"4111111111111111", "credit_card", true},
+		{"4111 1111 1111 1111", "credit_card", true},
+		{"5555-5555-5555-4444", "credit_card", true},
+		{"2223003122003222", "credit_card:mastercard", true},
+		{"378282246310005", "credit_card:visa,amex", true},
+		{"6011111111111117", "credit_card", true},
+		{"3530111333300000", "credit_card", true},
+		{"30569309025904", "credit_card", true},
+		{"4111111111111112", "credit_card", false},
+		{"4111111111111111", "credit_card:amex", false},
+		{"0000000000000000", "credit_card", false},
+		{"4111x11111111111", "credit_card", false},
+		{4111111111111111.0, "credit_card", false},
+
+		{"GB82 WEST 1234 5698 7654 32", "iban", true},
+		{"de89370400440532013000", "iban", true},
+		{"GB82WEST12345698765433", "iban", false},
+		{"GB82WEST123456987654", "iban", false},
+		{"ZZ82WEST12345698765432", "iban", false},
+		{1234, "iban", false},
+
+		{"DEUTDEFF", "bic", true},
+		{"DEUTDEFF500", "bic", true},
+		{"DEUT1EFF", "bic", false},
+		{"DEUTDEFF5", "bic", false},
+		{"deutdeff", "bic", false},
+
+		{"0-306-40615-2", "isbn", true},
+		{"080442957X", "isbn:10", true},
+		{"978-0-306-40615-7", "isbn", true},
+		{"978-0-306-40615-7", "isbn:10", false},
+		{"0-306-40615-3", "isbn", false},
+		{"400-6381-33393-1", "isbn", false},
+
+		{"4006381333931", "ean", true},
+		{"73513537", "ean:8", true},
+		{4006381333931, "ean:13", true},
+		{"4006381333932", "ean", false},
+		{"73513537", "ean:13", false},
+
+		{"036000291452", "upc", true},
+		{"036000291453", "upc", false},
+		{"4006381333931", "upc", false},
+	}
+
+	for _, tt := range tests {
+		validator := New(map[string]interface{}{"foo": tt.value}, map[string]interface{}{"foo": tt.rule})
+		if validator.Passes() != tt.pass {
+			t.Errorf("%s: expected %v for %v", tt.rule, tt.pass, tt.value)
+		}
+	}
+}
+
+func TestIdentifierRuleParameters(t *testing.T) {
+	for _, rule := range []string{"credit_card:maestro", "isbn:11", "ean:12"} {
+		err := New(map[string]interface{}{"foo": "4006381333931"}, map[string]interface{}{"foo": rule}).Validate()
+		if !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("%s: expected ErrInvalidParameter, got %v", rule, err)
+		}
+	}
+}
+
+func TestCardBrand(t *testing.T) {
+	tests := map[string]string{
+		"4111111111111111":    CardVisa,
+		"5555 5555 5555 4444": CardMastercard,
+		"2720990000000007":    CardMastercard,
+		"378282246310005":     CardAmex,
+		"6500000000000002":    CardDiscover,
+		"3589000000000003":    CardJCB,
+		"6200000000000005":    CardUnionPay,
+		"36000000000008":      CardDiners,
+		"1234567890123456":    "",
+		"41111":               "",
+	}
+
+	for number, brand := range tests {
+		if got := CardBrand(number); got != brand {
+			t.Errorf("%s: expected brand %q, got %q", number, brand, got)
+		}
+	}
+}
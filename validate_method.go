@@ -2,9 +2,12 @@ package validation
 
 import (
 	"fmt"
+	"math"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
@@ -16,51 +19,188 @@ const (
 
 func requireParameterCount(count int, parameters []string, rule string) {
 	if len(parameters) < count {
-		panic(fmt.Sprintf("validation: rule %s requires at least %d parameters.", rule, count))
+		panic(fmt.Errorf("%w: rule %s requires at least %d parameters", ErrInvalidParameter, rule, count))
 	}
 }
 
+// requireParameters panics with the error of check for parameters, such as
+// an unknown region of phone.
+func requireParameters(check func([]string) error, parameters []string) {
+	if err := check(parameters); err != nil {
+		panic(err)
+	}
+}
+
+// getSize returns the size of a value: a number of any integer or float kind
+// is its value, a string its number of characters.
 func getSize(rule string, value interface{}) (float64, error) {
-	switch value.(type) {
-	case int:
-		return float64(value.(int)), nil
-	case float64:
-		return value.(float64), nil
-	case string:
-		return float64(len(value.(string))), nil
-	default:
-		return 0.0, fmt.Errorf("validation: rule %s should only be used by the value of (float or string).", rule)
+	if s, ok := value.(string); ok {
+		return float64(utf8.RuneCountInString(s)), nil
 	}
+	if isNumberKind(value) {
+		if f, ok := toFloat(value); ok {
+			return f, nil
+		}
+	}
+
+	return 0.0, fmt.Errorf("validation: rule %s should only be used by the value of (float or string).", rule)
 }
 
 func getType(value interface{}) string {
-	switch value.(type) {
-	case int:
-		return "float"
-	case float64:
+	if isNumberKind(value) {
 		return "float"
-	case string:
-		return "string"
+	}
+
+	return "string"
+}
+
+// isNumberKind reports whether a value is of an integer or float kind.
+func isNumberKind(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// toString converts a scalar value (string, integer or float kinds, including
+// named types such as string based enums) to its string form.
+func toString(value interface{}) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), true
 	default:
-		return "string"
+		return "", false
+	}
+}
+
+// toStringSlice converts a slice or array of scalar values to strings.
+func toStringSlice(values interface{}) ([]string, bool) {
+	rv := reflect.ValueOf(values)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	strValues := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		strValue, ok := toString(rv.Index(i).Interface())
+		if !ok {
+			return nil, false
+		}
+		strValues = append(strValues, strValue)
+	}
+
+	return strValues, true
+}
+
+func inList(value interface{}, list []string, fold bool) bool {
+	strValue, ok := toString(value)
+	if !ok {
+		return false
+	}
+
+	for _, v := range list {
+		if strValue == v || (fold && strings.EqualFold(strValue, v)) {
+			return true
+		}
 	}
+
+	return false
 }
 
 func stringTofloat64(rule, s string) float64 {
 	f, err := strconv.ParseFloat(s, 64)
 	if err != nil {
-		panic(fmt.Sprintf("validation: invalid parameter for rule %s, a float string is required.", rule))
+		panic(fmt.Errorf("%w: rule %s requires a float string, got %q", ErrInvalidParameter, rule, s))
 	}
 
 	return f
 }
 
-func validateAlpha(attribute string, value interface{}, parameters []string) bool {
+// acceptedValues and declinedValues are the strings, compared case
+// insensitively, that check boxes and consent fields are commonly sent as.
+var (
+	acceptedValues = []string{"yes", "on", "1", "true"}
+	declinedValues = []string{"no", "off", "0", "false"}
+)
+
+// isConsent reports whether value is an accepted (or declined) bool, number
+// or string.
+func isConsent(value interface{}, accepted bool) bool {
+	if b, ok := value.(bool); ok {
+		return b == accepted
+	}
+
+	if accepted {
+		return inList(value, acceptedValues, true)
+	}
+
+	return inList(value, declinedValues, true)
+}
+
+// otherIn reports whether the attribute named by the first parameter has one
+// of the values of the remaining parameters.
+func otherIn(ctx *Context, rule string) bool {
+	requireParameterCount(2, ctx.Parameters, rule)
+
+	other := ctx.Get(ctx.Parameters[0])
+	if b, ok := other.(bool); ok {
+		other = strconv.FormatBool(b)
+	}
+
+	return inList(other, ctx.Parameters[1:], false)
+}
+
+// isEmpty reports whether value is missing, a blank string or an empty slice
+// or map.
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(rv.String()) == ""
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len() == 0
+	default:
+		return false
+	}
+}
+
+func validateAccepted(ctx *Context) bool {
+	return isConsent(ctx.Value, true)
+}
+
+func validateAcceptedIf(ctx *Context) bool {
+	return !otherIn(ctx, "accepted_if") || isConsent(ctx.Value, true)
+}
+
+func validateAlpha(ctx *Context) bool {
 	var strValue string
 
-	switch value.(type) {
+	switch ctx.Value.(type) {
 	case string:
-		strValue, _ = value.(string)
+		strValue, _ = ctx.Value.(string)
 	default:
 		return false
 	}
@@ -72,12 +212,12 @@ func validateAlpha(attribute string, value interface{}, parameters []string) boo
 	return true
 }
 
-func validateAlphaNum(attribute string, value interface{}, parameters []string) bool {
+func validateAlphaNum(ctx *Context) bool {
 	var strValue string
 
-	switch value.(type) {
+	switch ctx.Value.(type) {
 	case string:
-		strValue, _ = value.(string)
+		strValue, _ = ctx.Value.(string)
 	default:
 		return false
 	}
@@ -89,22 +229,22 @@ func validateAlphaNum(attribute string, value interface{}, parameters []string)
 	return true
 }
 
-func validateBetween(attribute string, value interface{}, parameters []string) bool {
-	requireParameterCount(2, parameters, "between")
-	size, err := getSize("between", value)
+func validateBetween(ctx *Context) bool {
+	requireParameterCount(2, ctx.Parameters, "between")
+	size, err := getSize("between", ctx.Value)
 	if err != nil {
 		return false
 	}
 
-	return size >= stringTofloat64("between", parameters[0]) && size <= stringTofloat64("between", parameters[1])
+	return size >= stringTofloat64("between", ctx.Parameters[0]) && size <= stringTofloat64("between", ctx.Parameters[1])
 }
 
-func validateBool(attribute string, value interface{}, parameters []string) bool {
-	if value == nil {
+func validateBool(ctx *Context) bool {
+	if ctx.Value == nil {
 		return false
 	}
 
-	switch value.(type) {
+	switch ctx.Value.(type) {
 	case bool:
 		return true
 	default:
@@ -112,12 +252,20 @@ func validateBool(attribute string, value interface{}, parameters []string) bool
 	}
 }
 
-func validateEmail(attribute string, value interface{}, parameters []string) bool {
+func validateDeclined(ctx *Context) bool {
+	return isConsent(ctx.Value, false)
+}
+
+func validateDeclinedIf(ctx *Context) bool {
+	return !otherIn(ctx, "declined_if") || isConsent(ctx.Value, false)
+}
+
+func validateEmail(ctx *Context) bool {
 	var strValue string
 
-	switch value.(type) {
+	switch ctx.Value.(type) {
 	case string:
-		strValue, _ = value.(string)
+		strValue, _ = ctx.Value.(string)
 	default:
 		return false
 	}
@@ -129,67 +277,94 @@ func validateEmail(attribute string, value interface{}, parameters []string) boo
 	return true
 }
 
-func validateFloat(attribute string, value interface{}, parameters []string) bool {
-	if value == nil {
+func excludeIf(ctx *Context) bool {
+	return otherIn(ctx, "exclude_if")
+}
+
+func excludeUnless(ctx *Context) bool {
+	return !otherIn(ctx, "exclude_unless")
+}
+
+func validateFloat(ctx *Context) bool {
+	if ctx.Value == nil {
 		return false
 	}
 
-	switch value.(type) {
-	case float64:
+	switch reflect.ValueOf(ctx.Value).Kind() {
+	case reflect.Float32, reflect.Float64:
 		return true
 	default:
 		return false
 	}
 }
 
-func validateIn(attribute string, value interface{}, parameters []string) bool {
-	requireParameterCount(1, parameters, "in")
+func validateIn(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "in")
 
-	var strValue string
-	switch value.(type) {
-	case int:
-		strValue = strconv.Itoa(value.(int))
-	case float64:
-		strValue = strconv.FormatFloat(value.(float64), 'f', -1, 64)
-	case string:
-		strValue = value.(string)
-	default:
+	return inList(ctx.Value, ctx.Parameters, false)
+}
+
+// validateInArray checks that the value is one of the values of the list
+// attribute referenced by the parameter, such as "other.*".
+func validateInArray(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "in_array")
+
+	values, ok := toStringSlice(ctx.Get(strings.TrimSuffix(ctx.Parameters[0], ".*")))
+	if !ok {
 		return false
 	}
 
-	for _, v := range parameters {
-		if strValue == v {
-			return true
-		}
+	return inList(ctx.Value, values, false)
+}
+
+func validateInCi(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "in_ci")
+
+	return inList(ctx.Value, ctx.Parameters, true)
+}
+
+func validateInteger(ctx *Context) bool {
+	if ctx.Value == nil {
+		return false
 	}
 
-	return false
+	rv := reflect.ValueOf(ctx.Value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return f == math.Trunc(f) && !math.IsInf(f, 0)
+	default:
+		return false
+	}
 }
 
-func validateMax(attribute string, value interface{}, parameters []string) bool {
-	requireParameterCount(1, parameters, "max")
+func validateMax(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "max")
 
-	size, err := getSize("max", value)
+	size, err := getSize("max", ctx.Value)
 	if err != nil {
 		return false
 	}
 
-	return size <= stringTofloat64("max", parameters[0])
+	return size <= stringTofloat64("max", ctx.Parameters[0])
 }
 
-func validateMin(attribute string, value interface{}, parameters []string) bool {
-	requireParameterCount(1, parameters, "min")
+func validateMin(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "min")
 
-	size, err := getSize("min", value)
+	size, err := getSize("min", ctx.Value)
 	if err != nil {
 		return false
 	}
 
-	return size >= stringTofloat64("min", parameters[0])
+	return size >= stringTofloat64("min", ctx.Parameters[0])
 }
 
-func validateNum(attribute string, value interface{}, parameters []string) bool {
-	strValue, ok := value.(string)
+func validateNum(ctx *Context) bool {
+	strValue, ok := ctx.Value.(string)
 	if !ok {
 		return false
 	}
@@ -200,28 +375,98 @@ func validateNum(attribute string, value interface{}, parameters []string) bool
 	return true
 }
 
-func validateRegex(attribute string, value interface{}, parameters []string) bool {
-	requireParameterCount(1, parameters, "regex")
+func validateNotIn(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "not_in")
+
+	if _, ok := toString(ctx.Value); !ok {
+		return false
+	}
 
-	strValue, ok := value.(string)
+	return !inList(ctx.Value, ctx.Parameters, false)
+}
+
+func validateNotInCi(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "not_in_ci")
+
+	if _, ok := toString(ctx.Value); !ok {
+		return false
+	}
+
+	return !inList(ctx.Value, ctx.Parameters, true)
+}
+
+func validateProhibited(ctx *Context) bool {
+	return isEmpty(ctx.Value)
+}
+
+func validateProhibitedIf(ctx *Context) bool {
+	return !otherIn(ctx, "prohibited_if") || isEmpty(ctx.Value)
+}
+
+func validateProhibitedUnless(ctx *Context) bool {
+	return otherIn(ctx, "prohibited_unless") || isEmpty(ctx.Value)
+}
+
+func validateProhibits(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "prohibits")
+
+	if isEmpty(ctx.Value) {
+		return true
+	}
+	for _, other := range ctx.Parameters {
+		if !isEmpty(ctx.Get(other)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateRegex(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "regex")
+
+	strValue, ok := ctx.Value.(string)
 	if !ok {
 		return false
 	}
-	if matched, _ := regexp.MatchString(parameters[0], strValue); !matched {
+	if matched, _ := regexp.MatchString(ctx.Parameters[0], strValue); !matched {
 		return false
 	}
 
 	return true
 }
 
-func validateRequired(attribute string, value interface{}, parameters []string) bool {
-	if value == nil {
+// validateRequiredWith requires the attribute when any of the attributes
+// given as parameters is present. A "*" in a parameter stands for the
+// segment of the attribute at the same position, so that "items.*.address"
+// refers to the address of the same item as "items.*.address.city".
+func validateRequiredWith(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "required_with")
+
+	segments := strings.Split(ctx.Attribute, ".")
+	for _, parameter := range ctx.Parameters {
+		others := strings.Split(parameter, ".")
+		for i, other := range others {
+			if other == "*" && i < len(segments) {
+				others[i] = segments[i]
+			}
+		}
+		if !isEmpty(ctx.Get(strings.Join(others, "."))) {
+			return validateRequired(ctx)
+		}
+	}
+
+	return true
+}
+
+func validateRequired(ctx *Context) bool {
+	if ctx.Value == nil {
 		return false
 	}
 
-	switch value.(type) {
+	switch ctx.Value.(type) {
 	case string:
-		strValue, ok := value.(string)
+		strValue, ok := ctx.Value.(string)
 		if !ok {
 			return false
 		}
@@ -233,23 +478,23 @@ func validateRequired(attribute string, value interface{}, parameters []string)
 	return true
 }
 
-func validateSize(attribute string, value interface{}, parameters []string) bool {
-	requireParameterCount(1, parameters, "size")
+func validateSize(ctx *Context) bool {
+	requireParameterCount(1, ctx.Parameters, "size")
 
-	size, err := getSize("size", value)
+	size, err := getSize("size", ctx.Value)
 	if err != nil {
 		return false
 	}
 
-	return size == stringTofloat64("size", parameters[0])
+	return size == stringTofloat64("size", ctx.Parameters[0])
 }
 
-func validateString(attribute string, value interface{}, parameters []string) bool {
-	if value == nil {
+func validateString(ctx *Context) bool {
+	if ctx.Value == nil {
 		return false
 	}
 
-	switch value.(type) {
+	switch ctx.Value.(type) {
 	case string:
 		return true
 	default:
@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//go:embed data/phone.tsv
+var phoneData []byte
+
+// phonePlan is the simplified numbering plan of a region.
+type phonePlan struct {
+	region         string
+	code           string
+	nationalPrefix string
+	pattern        *regexp.Regexp
+}
+
+var (
+	phonePlansOnce sync.Once
+	phonePlans     []phonePlan
+	phoneRegions   map[string]phonePlan
+
+	regexpE164       = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+	phoneSeparators  = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+	regexpPhoneChars = regexp.MustCompile(`^\+?\d+$`)
+)
+
+// loadPhonePlans parses the embedded numbering plans once.
+func loadPhonePlans() {
+	phonePlansOnce.Do(func() {
+		phoneRegions = map[string]phonePlan{}
+
+		scanner := bufio.NewScanner(bytes.NewReader(phoneData))
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			fields := strings.Split(line, "\t")
+			plan := phonePlan{
+				region:         fields[0],
+				code:           fields[1],
+				nationalPrefix: strings.TrimPrefix(fields[2], "-"),
+				pattern:        regexp.MustCompile("^(?:" + fields[3] + ")$"),
+			}
+			phonePlans = append(phonePlans, plan)
+			phoneRegions[plan.region] = plan
+		}
+	})
+}
+
+// NormalizePhone returns a phone number in E.164 form, such as "+14155552671",
+// and whether it is valid. The number may contain spaces, hyphens, dots and
+// parentheses. An international number starts with + or 00; a national
+// number, with or without its national prefix, is accepted for the given
+// regions (ISO 3166-1 alpha-2 codes such as "US"). When regions are given,
+// an international number must belong to one of them.
+//
+// Numbers are checked against simplified numbering plans of a limited set of
+// regions; no number is valid for a given region without a plan. Without
+// regions, an international number whose country calling code has no plan is
+// only checked for the E.164 syntax. Regions sharing a country calling code
+// are not told apart by area code: the regions of the North American
+// Numbering Plan share +1 and the same plan, so a Canadian number is valid
+// for "US".
+func NormalizePhone(number string, regions ...string) (string, bool) {
+	loadPhonePlans()
+
+	plans := make([]phonePlan, 0, len(regions))
+	for _, region := range regions {
+		plan, ok := phoneRegions[strings.ToUpper(region)]
+		if !ok {
+			return "", false
+		}
+		plans = append(plans, plan)
+	}
+
+	number = phoneSeparators.Replace(number)
+	if !regexpPhoneChars.MatchString(number) {
+		return "", false
+	}
+	if strings.HasPrefix(number, "00") {
+		number = "+" + number[2:]
+	}
+
+	if strings.HasPrefix(number, "+") {
+		if !regexpE164.MatchString(number) {
+			return "", false
+		}
+
+		known := false
+		candidates := plans
+		if len(regions) == 0 {
+			candidates = phonePlans
+		}
+		for _, plan := range candidates {
+			if !strings.HasPrefix(number[1:], plan.code) {
+				continue
+			}
+			known = true
+			if plan.pattern.MatchString(number[1+len(plan.code):]) {
+				return number, true
+			}
+		}
+
+		// Without a plan for the country calling code, accept the syntax.
+		if !known && len(regions) == 0 {
+			return number, true
+		}
+
+		return "", false
+	}
+
+	for _, plan := range plans {
+		nsn := number
+		if plan.nationalPrefix != "" && strings.HasPrefix(nsn, plan.nationalPrefix) && !plan.pattern.MatchString(nsn) {
+			nsn = nsn[len(plan.nationalPrefix):]
+		}
+		if plan.pattern.MatchString(nsn) {
+			return "+" + plan.code + nsn, true
+		}
+	}
+
+	return "", false
+}
+
+// checkPhoneParameters checks that the parameters of phone are e164 or
+// regions with a numbering plan.
+func checkPhoneParameters(parameters []string) error {
+	loadPhonePlans()
+
+	for _, parameter := range parameters {
+		if _, ok := phoneRegions[strings.ToUpper(parameter)]; !ok && !strings.EqualFold(parameter, "e164") {
+			return fmt.Errorf("%w: rule phone has an unknown region %q", ErrInvalidParameter, parameter)
+		}
+	}
+
+	return nil
+}
+
+// validatePhone checks a phone number. "phone" and "phone:e164" accept
+// international numbers, "phone:e164" only in strict E.164 form without
+// separators. Regions ("phone:US,GB") accept the national and international
+// numbers of those regions, and may be combined with e164.
+func validatePhone(ctx *Context) bool {
+	_, ok := normalizePhoneValue(ctx)
+	return ok
+}
+
+// normalizePhoneValue returns the E.164 form of a phone number valid for the
+// parameters of the phone rule.
+func normalizePhoneValue(ctx *Context) (interface{}, bool) {
+	requireParameters(checkPhoneParameters, ctx.Parameters)
+
+	strict := false
+	regions := make([]string, 0, len(ctx.Parameters))
+	for _, parameter := range ctx.Parameters {
+		if strings.EqualFold(parameter, "e164") {
+			strict = true
+		} else {
+			regions = append(regions, parameter)
+		}
+	}
+
+	number, ok := ctx.Value.(string)
+	if !ok || (strict && !regexpE164.MatchString(number)) {
+		return nil, false
+	}
+
+	return NormalizePhone(number, regions...)
+}
@@ -0,0 +1,109 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPhoneRule(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		rule  string
+		pass  bool
+	}{
+		{"+14155552671", "phone", true},
+		{"+44 20 7946 0958", "phone", true},
+		{"0044 20 7946 0958", "phone", true},
+		{"+999 1234 5678", "phone", true},
+		{"+1 155 555 2671", "phone", false},
+		{"(415) 555-2671", "phone", false},
+		{"+1234567890123456", "phone", false},
+		{14155552671, "phone", false},
+
+		{"+14155552671", "phone:e164", true},
+		{"+1 415 555 2671", "phone:e164", false},
+		{"14155552671", "phone:e164", false},
+
+		{"(415) 555-2671", "phone:US,GB", true},
+		{"1-415-555-2671", "phone:US", true},
+		{"020 7946 0958", "phone:US,GB", true},
+		{"+44 20 7946 0958", "phone:US,GB", true},
+		{"+33 1 23 45 67 89", "phone:US,GB", false},
+		{"+441234", "phone:GB", false},
+		{"020 7946 0958", "phone:US", false},
+		{"+442079460958", "phone:e164,GB", true},
+		{"020 7946 0958", "phone:e164,GB", false},
+	}
+
+	for _, tt := range tests {
+		validator := New(map[string]interface{}{"foo": tt.value}, map[string]interface{}{"foo": tt.rule})
+		if validator.Passes() != tt.pass {
+			t.Errorf("%s: expected %v for %v", tt.rule, tt.pass, tt.value)
+		}
+	}
+
+	err := New(map[string]interface{}{"foo": "+14155552671"}, map[string]interface{}{"foo": "phone:XX"}).Validate()
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for an unknown region, got %v", err)
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		number  string
+		regions []string
+		e164    string
+	}{
+		{"+1 (415) 555-2671", nil, "+14155552671"},
+		{"00 44 20 7946 0958", nil, "+442079460958"},
+		{"(415) 555.2671", []string{"US"}, "+14155552671"},
+		{"020 7946 0958", []string{"us", "gb"}, "+442079460958"},
+		{"8 495 123 45 67", []string{"RU"}, "+74951234567"},
+		{"020 7946 0958", nil, ""},
+		{"+14155552671", []string{"XX"}, ""},
+	}
+
+	for _, tt := range tests {
+		e164, ok := NormalizePhone(tt.number, tt.regions...)
+		if e164 != tt.e164 || ok != (tt.e164 != "") {
+			t.Errorf("%s %v: expected %q, got %q, %v", tt.number, tt.regions, tt.e164, e164, ok)
+		}
+	}
+}
+
+func TestPhoneValidated(t *testing.T) {
+	data := map[string]interface{}{
+		"phone":    "(415) 555-2671",
+		"contacts": []interface{}{map[string]interface{}{"phone": "020 7946 0958"}},
+	}
+	rules := map[string]interface{}{
+		"phone":            "required|phone:US",
+		"contacts":         "required",
+		"contacts.*.phone": "phone:GB",
+	}
+
+	validated, err := New(data, rules).Validated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if validated["phone"] != "(415) 555-2671" {
+		t.Errorf("expected the submitted number without WithNormalization, got %v", validated["phone"])
+	}
+
+	validated, err = New(data, rules, WithNormalization()).Validated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	contacts := validated["contacts"].([]interface{})
+	if validated["phone"] != "+14155552671" || contacts[0].(map[string]interface{})["phone"] != "+442079460958" {
+		t.Errorf("expected E.164 numbers, got %v", validated)
+	}
+	if data["contacts"].([]interface{})[0].(map[string]interface{})["phone"] != "020 7946 0958" {
+		t.Errorf("expected the data to be left as is, got %v", data)
+	}
+
+	// The regions of the North American Numbering Plan share +1.
+	if !New(map[string]interface{}{"phone": "+1 613 555 0123"}, map[string]interface{}{"phone": "phone:US"}).Passes() {
+		t.Errorf("expected a Canadian number to pass phone:US")
+	}
+}
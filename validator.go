@@ -1,57 +1,150 @@
 package validation
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 )
 
+// validator is safe for concurrent use. The data is validated once, on the
+// first call that needs the result, and the result is reused afterwards.
 type validator struct {
-	data    map[string]interface{}
-	rules   map[string][]string
-	message string
+	data           map[string]interface{}
+	ruleSet        *RuleSet
+	concurrency    int
+	messages       map[string]string
+	attributeNames map[string]string
+	ctx            context.Context
+	locale         string
+	breached       BreachedList
+	normalize      bool
+
+	once     sync.Once
+	errors   ValidationErrors
+	included []target
+	excluded []target
+	failure  interface{}
 }
 
-func New(data map[string]interface{}, rules map[string]interface{}) *validator {
-	return &validator{
-		data:  data,
-		rules: explodeRules(rules),
+// Option configures a validator.
+type Option func(*validator)
+
+// WithConcurrency validates up to n attributes at the same time. It pays off
+// for records with many attributes or rules that do I/O; errors are still
+// reported in attribute order.
+func WithConcurrency(n int) Option {
+	return func(v *validator) {
+		v.concurrency = n
 	}
 }
 
-func explodeRules(rules map[string]interface{}) map[string][]string {
-	r := map[string][]string{}
+// WithMessages overrides the default messages. Keys are either a rule name,
+// or an attribute and a rule name joined by a dot ("email.required"), which
+// takes precedence. The requirements of rules such as password are keyed by
+// the rule and the requirement joined by a colon ("password:min", or
+// "new_password.password:min" for one attribute), so they never match a
+// rule of an attribute named after the rule. Messages may use the same
+// placeholders as the defaults.
+func WithMessages(messages map[string]string) Option {
+	return func(v *validator) {
+		v.messages = messages
+	}
+}
 
-	for attribute, rule := range rules {
-		switch rule.(type) {
-		case string:
-			strRule, _ := rule.(string)
-			r[attribute] = strings.Split(strRule, "|")
-		case []string:
-			sliceRule, _ := rule.([]string)
-			r[attribute] = sliceRule
-		default:
-			panic(fmt.Sprintf("validation: invalid rule type for attribute %s, string or []string expected.", attribute))
-		}
+// WithAttributeNames sets the names used for :attribute in messages.
+func WithAttributeNames(names map[string]string) Option {
+	return func(v *validator) {
+		v.attributeNames = names
+	}
+}
 
-		if !isValidRules(r[attribute]) {
-			panic(fmt.Sprintf("validation: invalid rule for attribute %s", attribute))
-		}
+// WithContext sets the context.Context of the rules, see Context.
+func WithContext(ctx context.Context) Option {
+	return func(v *validator) {
+		v.ctx = ctx
 	}
+}
 
-	return r
+// WithBreachedList sets the list of breached passwords checked by the
+// uncompromised requirement of the password rule.
+func WithBreachedList(list BreachedList) Option {
+	return func(v *validator) {
+		v.breached = list
+	}
 }
 
-func isValidRules(rules []string) bool {
-	if len(rules) == 0 {
-		return false
+// WithLocale sets the locale passed to the rules, see Context.
+func WithLocale(locale string) Option {
+	return func(v *validator) {
+		v.locale = locale
 	}
-	for _, rule := range rules {
-		if rule == "" {
-			return false
-		}
+}
+
+// WithNormalization makes Validated return the values of rules with a normal
+// form in that form, such as phone numbers in E.164 form, instead of the
+// submitted values.
+func WithNormalization() Option {
+	return func(v *validator) {
+		v.normalize = true
+	}
+}
+
+// New returns a validator for data. Attributes are validated in the sorted
+// order of their names.
+//
+// An attribute is looked up as a key of data first. Only when there is no
+// such key, a dotted attribute ("address.city") is a path into nested maps
+// and slices, and a "*" segment ("tags.*") stands for every element present
+// in the data, so that a wildcard matching nothing has nothing to validate.
+func New(data map[string]interface{}, rules map[string]interface{}, opts ...Option) *validator {
+	return NewRuleSet(rules, opts...).New(data)
+}
+
+// NewOrdered returns a validator for data that validates the attributes in
+// the order they are declared in rules.
+func NewOrdered(data map[string]interface{}, rules []AttributeRules, opts ...Option) *validator {
+	return NewOrderedRuleSet(rules, opts...).New(data)
+}
+
+// Valuer is implemented by enum-like types that can list their allowed values.
+type Valuer interface {
+	Values() []string
+}
+
+// In returns an "in" rule accepting the given values. values may be a slice or
+// array of strings, integers or floats, or a Valuer. Unlike a rule string, the
+// values may contain commas.
+func In(values interface{}) string {
+	return listRule("in", values)
+}
+
+// NotIn returns a "not_in" rule rejecting the given values. values are
+// accepted in the same forms as In.
+func NotIn(values interface{}) string {
+	return listRule("not_in", values)
+}
+
+func listRule(rule string, values interface{}) string {
+	var strValues []string
+	if valuer, ok := values.(Valuer); ok {
+		strValues = valuer.Values()
+	} else if strValues, ok = toStringSlice(values); !ok {
+		panic(fmt.Errorf("%w: rule %s requires a slice of scalars or a Valuer", ErrInvalidParameter, rule))
+	}
+
+	if len(strValues) == 0 {
+		panic(fmt.Errorf("%w: rule %s requires at least 1 parameters", ErrInvalidParameter, rule))
+	}
+
+	escaped := make([]string, len(strValues))
+	for i, value := range strValues {
+		escaped[i] = escapeParameter(value)
 	}
 
-	return true
+	return rule + ":" + strings.Join(escaped, ",")
 }
 
 func (v *validator) Fails() bool {
@@ -59,95 +152,383 @@ func (v *validator) Fails() bool {
 }
 
 func (v *validator) Passes() bool {
-	for attribute, rules := range v.rules {
-		for _, rule := range rules {
-			if !v.validate(attribute, rule) {
-				return false
-			}
-		}
+	return len(v.result()) == 0
+}
+
+// GetMessage returns the message of the first invalid attribute, or an empty
+// string when the data is valid.
+func (v *validator) GetMessage() string {
+	errs := v.result()
+	if len(errs) == 0 {
+		return ""
 	}
 
-	return true
+	return errs[0].Message
 }
 
-func (v *validator) GetMessage() string {
-	return v.message
+// Errors validates the data and returns the errors of the first failing rule
+// of each invalid attribute: one error, or one per unmet requirement of a
+// rule such as password.
+func (v *validator) Errors() ValidationErrors {
+	errs := v.result()
+
+	return append(ValidationErrors{}, errs...)
 }
 
-func (v *validator) validate(attribute, rule string) bool {
-	rule, parameters := parseRule(rule)
-	value := v.getValue(attribute)
+// result returns the memoised errors of the validation run. A panic raised by
+// the rules is memoised as well and raised again on every call.
+func (v *validator) result() ValidationErrors {
+	v.once.Do(func() {
+		defer func() {
+			v.failure = recover()
+		}()
+		v.errors, v.included, v.excluded = v.run()
+	})
 
-	if rule != "required" && value == nil {
-		return true
+	if v.failure != nil {
+		panic(v.failure)
 	}
 
-	method, err := getRuleMethod(rule)
-	if err != nil {
-		panic(err)
+	return v.errors
+}
+
+// run validates every attribute; an attribute stops at its first failing rule.
+// It returns the errors and the attributes that were and were not excluded.
+func (v *validator) run() (ValidationErrors, []target, []target) {
+	if v.concurrency > 1 {
+		return v.runConcurrent()
 	}
 
-	// Call the method of rule.
-	if !method(attribute, value, parameters) {
-		var message string
-		if rule == "max" || rule == "min" || rule == "size" || rule == "between" {
-			message, _ = defaultRuleMessages2[rule][getType(value)]
+	errs := ValidationErrors{}
+	included, excluded := []target{}, []target{}
+	for _, target := range v.targets() {
+		attributeErrs, isExcluded := v.validateAttribute(target)
+		errs = append(errs, attributeErrs...)
+		if isExcluded {
+			excluded = append(excluded, target)
 		} else {
-			message, _ = defaultRuleMessages[rule]
+			included = append(included, target)
 		}
+	}
+
+	return errs, included, excluded
+}
+
+// runConcurrent validates the attributes with a pool of v.concurrency
+// workers. A panic in a worker is raised again once all workers are done.
+func (v *validator) runConcurrent() (ValidationErrors, []target, []target) {
+	targets := v.targets()
+	results := make([]ValidationErrors, len(targets))
+	excluded := make([]bool, len(targets))
+
+	parallel(v.concurrency, len(targets), func(i int) {
+		results[i], excluded[i] = v.validateAttribute(targets[i])
+	})
 
-		if rule == "size" {
-			message = strings.Replace(message, ":size", parameters[0], -1)
-		} else if rule == "max" {
-			message = strings.Replace(message, ":max", parameters[0], -1)
-		} else if rule == "min" {
-			message = strings.Replace(message, ":min", parameters[0], -1)
-		} else if rule == "between" {
-			message = strings.Replace(message, ":min", parameters[0], -1)
-			message = strings.Replace(message, ":max", parameters[1], -1)
-		} else if rule == "in" {
-			message = strings.Replace(message, ":values", strings.Join(parameters, ","), -1)
+	errs := ValidationErrors{}
+	included, excludedTargets := []target{}, []target{}
+	for i, attributeErrs := range results {
+		errs = append(errs, attributeErrs...)
+		if excluded[i] {
+			excludedTargets = append(excludedTargets, targets[i])
+		} else {
+			included = append(included, targets[i])
 		}
-		message = strings.Replace(message, ":attribute", attribute, -1)
-		v.message = message
+	}
+
+	return errs, included, excludedTargets
+}
 
-		return false
+// parallel calls fn for the indexes 0 to n-1 with a pool of workers. A panic
+// in fn is raised again in the calling goroutine once all workers are done.
+func parallel(workers, n int, fn func(i int)) {
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failure interface{}
+
+	for i := 0; i < workers && i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					mu.Lock()
+					if failure == nil {
+						failure = r
+					}
+					mu.Unlock()
+					// Drain the remaining indexes so the producer is not blocked.
+					for range indexes {
+					}
+				}
+			}()
+
+			for i := range indexes {
+				fn(i)
+			}
+		}()
 	}
 
-	return true
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if failure != nil {
+		panic(failure)
+	}
 }
 
-func parseRule(rule string) (string, []string) {
-	parameters := []string{}
-	if strings.Index(rule, ":") != -1 {
-		splitRule := strings.SplitN(rule, ":", 2)
-		rule = splitRule[0]
-		parameters = parseParameters(splitRule[0], splitRule[1])
+// validateAttribute validates an attribute and reports whether an exclude
+// rule removed it from validation.
+func (v *validator) validateAttribute(t target) (ValidationErrors, bool) {
+	rules := v.ruleSet.rules[t.pattern]
+	for _, rule := range rules {
+		if !exclusionRules[rule.name] {
+			continue
+		}
+		ctx := v.newContext(t.attribute, v.getValue(t.attribute), rule.parameters)
+		if ruleMethodMap[rule.name](ctx) {
+			return nil, true
+		}
 	}
 
-	return rule, parameters
+	for _, rule := range rules {
+		if exclusionRules[rule.name] {
+			continue
+		}
+		if errs := v.validate(t, rule); len(errs) > 0 {
+			return errs, false
+		}
+	}
+
+	return nil, false
 }
 
-func (v *validator) getValue(attribute string) interface{} {
-	value, ok := v.data[attribute]
-	if !ok {
+// Validate validates the data and returns nil, the ValidationErrors of the
+// invalid attributes, or an error wrapping ErrRuleNotSupported or
+// ErrInvalidParameter when the rules themselves are invalid.
+func (v *validator) Validate() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok && isConfigError(e) {
+				err = e
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	if v.Passes() {
 		return nil
 	}
 
-	return value
+	return v.Errors()
+}
+
+// Validated validates the data and returns the values of the attributes that
+// have rules and were not excluded, nested as in the data, along with the
+// error returned by Validate. With WithNormalization, the values of rules with
+// a normal form are normalized, such as phone numbers to E.164; the data is
+// not modified.
+func (v *validator) Validated() (map[string]interface{}, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+
+	included := map[string]bool{}
+	for _, t := range v.included {
+		included[t.attribute] = true
+	}
+
+	validated := map[string]interface{}{}
+	for _, t := range v.included {
+		if !hasIncludedAncestor(included, t.attribute) {
+			copyPath(validated, v.data, t.attribute)
+		}
+	}
+
+	// An excluded attribute is dropped from the included ancestor holding it,
+	// the last first so the indexes of the earlier slice elements still hold.
+	for i := len(v.excluded) - 1; i >= 0; i-- {
+		t := v.excluded[i]
+		if _, ok := v.data[t.attribute]; !ok && hasIncludedAncestor(included, t.attribute) {
+			validated = removePath(validated, strings.Split(t.attribute, ".")).(map[string]interface{})
+		}
+	}
+
+	if !v.normalize {
+		return validated, nil
+	}
+	for _, t := range v.included {
+		value := v.getValue(t.attribute)
+		if value == nil {
+			continue
+		}
+		for _, r := range v.ruleSet.rules[t.pattern] {
+			normalize, ok := normalizerRuleMap[r.name]
+			if !ok || r.custom != nil {
+				continue
+			}
+			if normalized, ok := normalize(v.newContext(t.attribute, value, r.parameters)); ok {
+				value = normalized
+				if _, ok := v.data[t.attribute]; ok {
+					validated[t.attribute] = value
+				} else {
+					validated = replacePath(validated, strings.Split(t.attribute, "."), value).(map[string]interface{})
+				}
+			}
+		}
+	}
+
+	return validated, nil
 }
 
-func parseParameters(rule, parameter string) []string {
-	parameters := []string{}
-	if rule == "regex" {
-		parameters = append(parameters, parameter)
+// validate applies a rule to an attribute and returns its failures: none,
+// one, or one per unmet requirement of a rule such as password.
+func (v *validator) validate(t target, r rule) ValidationErrors {
+	attribute := t.attribute
+	value := v.getValue(attribute)
+
+	// Only implicit rules apply to a missing value.
+	if !r.implicit && value == nil {
+		return nil
+	}
+
+	ctx := v.newContext(attribute, value, r.parameters)
+
+	// keys are the message keys of the failures, such as "password:min".
+	var keys []string
+	var failures []*Failure
+	if r.custom != nil {
+		if err := r.custom(ctx); err != nil {
+			if isConfigError(err) {
+				panic(err)
+			}
+			var failure *Failure
+			if !errors.As(err, &failure) {
+				failure = &Failure{Message: err.Error()}
+			}
+			keys, failures = append(keys, r.name), append(failures, failure)
+		}
+	} else if check, ok := requirementRuleMap[r.name]; ok {
+		for _, requirement := range check(ctx) {
+			keys = append(keys, r.name+":"+requirement)
+			failures = append(failures, &Failure{Meta: map[string]interface{}{"requirement": requirement}})
+		}
 	} else {
-		parameters = strings.Split(parameter, ",")
+		method, err := getRuleMethod(r.name)
+		if err != nil {
+			panic(err)
+		}
+
+		// Call the method of rule.
+		if !method(ctx) {
+			keys, failures = append(keys, r.name), append(failures, &Failure{})
+		}
+	}
+
+	sensitive := false
+	for _, attributeRule := range v.ruleSet.rules[t.pattern] {
+		sensitive = sensitive || sensitiveRules[attributeRule.name]
+	}
+
+	var errs ValidationErrors
+	for i, failure := range failures {
+		message := v.getMessage(t, r, keys[i], value, failure.Message)
+		errs = append(errs, &FieldError{
+			Field:      attribute,
+			Rule:       r.name,
+			Parameters: r.parameters,
+			Message:    v.replacePlaceholders(t, r, message),
+			Pattern:    t.pattern,
+			Sensitive:  sensitive,
+			Meta:       failure.Meta,
+		})
+	}
+
+	return errs
+}
+
+// replacePlaceholders replaces the placeholders of a message of rule r.
+func (v *validator) replacePlaceholders(t target, r rule, message string) string {
+	parameters := r.parameters
+	if r.name == "size" {
+		message = strings.Replace(message, ":size", parameters[0], -1)
+	} else if r.name == "max" {
+		message = strings.Replace(message, ":max", parameters[0], -1)
+	} else if r.name == "min" {
+		message = strings.Replace(message, ":min", parameters[0], -1)
+	} else if r.name == "between" {
+		message = strings.Replace(message, ":min", parameters[0], -1)
+		message = strings.Replace(message, ":max", parameters[1], -1)
+	} else if r.name == "in" || r.name == "in_ci" {
+		message = strings.Replace(message, ":values", strings.Join(parameters, ","), -1)
+	} else if r.name == "accepted_if" || r.name == "declined_if" || r.name == "prohibited_if" {
+		message = strings.Replace(message, ":other", parameters[0], -1)
+		message = strings.Replace(message, ":value", parameters[1], -1)
+	} else if r.name == "prohibited_unless" {
+		message = strings.Replace(message, ":other", parameters[0], -1)
+		message = strings.Replace(message, ":values", strings.Join(parameters[1:], ", "), -1)
+	} else if r.name == "required_with" {
+		message = strings.Replace(message, ":values", strings.Join(parameters, ", "), -1)
+	} else if r.name == "prohibits" {
+		message = strings.Replace(message, ":other", strings.Join(parameters, ", "), -1)
+	} else if r.name == "in_array" {
+		message = strings.Replace(message, ":other", strings.TrimSuffix(parameters[0], ".*"), -1)
+	} else if r.name == "within_bbox" {
+		message = strings.Replace(message, ":min_lat", parameters[0], -1)
+		message = strings.Replace(message, ":min_lng", parameters[1], -1)
+		message = strings.Replace(message, ":max_lat", parameters[2], -1)
+		message = strings.Replace(message, ":max_lng", parameters[3], -1)
+	} else if r.name == "password" {
+		policy, _ := parsePasswordPolicy(parameters)
+		message = strings.Replace(message, ":min", strconv.Itoa(policy.min), -1)
 	}
 
-	for key, value := range parameters {
-		parameters[key] = strings.TrimSpace(value)
+	return strings.Replace(message, ":attribute", v.getAttributeName(t), -1)
+}
+
+// getMessage returns the message template for a failed rule: a custom message
+// for "attribute.key" (the attribute as declared in the rules, wildcards
+// included), then for "key", then the message of the rule failure, then the
+// default message. The key is the rule name, or the rule name and the unmet
+// requirement joined by a colon ("password:min").
+func (v *validator) getMessage(t target, r rule, key string, value interface{}, ruleMessage string) string {
+	rule := key
+	if message, ok := v.messages[t.attribute+"."+rule]; ok {
+		return message
+	}
+	if message, ok := v.messages[t.pattern+"."+rule]; ok {
+		return message
+	}
+	if message, ok := v.messages[rule]; ok {
+		return message
+	}
+	if ruleMessage != "" {
+		return ruleMessage
+	}
+	if r.custom != nil {
+		return defaultRuleMessages["custom"]
+	}
+
+	if rule == "max" || rule == "min" || rule == "size" || rule == "between" {
+		return defaultRuleMessages2[rule][getType(value)]
+	}
+
+	return defaultRuleMessages[rule]
+}
+
+func (v *validator) getAttributeName(t target) string {
+	if name, ok := v.attributeNames[t.attribute]; ok {
+		return name
+	}
+	if name, ok := v.attributeNames[t.pattern]; ok {
+		return name
 	}
 
-	return parameters
+	return t.attribute
 }
@@ -1,6 +1,9 @@
 package validation
 
 import (
+	"errors"
+	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -10,6 +13,139 @@ func TestRules(t *testing.T) {
 		rules map[string]interface{}
 		pass  bool
 	}{
+		// accepted and declined rules
+		// ======================================================
+		"accepted-true1": {
+			map[string]interface{}{"foo": "yes"},
+			map[string]interface{}{"foo": "accepted"},
+			true,
+		},
+		"accepted-true2": {
+			map[string]interface{}{"foo": "on"},
+			map[string]interface{}{"foo": "accepted"},
+			true,
+		},
+		"accepted-true3": {
+			map[string]interface{}{"foo": "1"},
+			map[string]interface{}{"foo": "accepted"},
+			true,
+		},
+		"accepted-true4": {
+			map[string]interface{}{"foo": "ON"},
+			map[string]interface{}{"foo": "accepted"},
+			true,
+		},
+		"accepted-true5": {
+			map[string]interface{}{"foo": 1},
+			map[string]interface{}{"foo": "accepted"},
+			true,
+		},
+		"accepted-true6": {
+			map[string]interface{}{"foo": 1.0},
+			map[string]interface{}{"foo": "accepted"},
+			true,
+		},
+		"accepted-true7": {
+			map[string]interface{}{"foo": true},
+			map[string]interface{}{"foo": "accepted"},
+			true,
+		},
+		"accepted-false1": {
+			map[string]interface{}{"foo": "no"},
+			map[string]interface{}{"foo": "accepted"},
+			false,
+		},
+		"accepted-false2": {
+			map[string]interface{}{"foo": "2"},
+			map[string]interface{}{"foo": "accepted"},
+			false,
+		},
+		"accepted-false3": {
+			map[string]interface{}{"foo": 0},
+			map[string]interface{}{"foo": "accepted"},
+			false,
+		},
+		"accepted-false4": {
+			map[string]interface{}{"foo": false},
+			map[string]interface{}{"foo": "accepted"},
+			false,
+		},
+		"accepted-false5": {
+			map[string]interface{}{},
+			map[string]interface{}{"foo": "accepted"},
+			false,
+		},
+		"accepted_if-true1": {
+			map[string]interface{}{"type": "business"},
+			map[string]interface{}{"terms": "accepted_if:type,personal"},
+			true,
+		},
+		"accepted_if-true2": {
+			map[string]interface{}{"type": "personal", "terms": "on"},
+			map[string]interface{}{"terms": "accepted_if:type,personal"},
+			true,
+		},
+		"accepted_if-false1": {
+			map[string]interface{}{"type": "personal"},
+			map[string]interface{}{"terms": "accepted_if:type,personal"},
+			false,
+		},
+		"accepted_if-false2": {
+			map[string]interface{}{"marketing": true, "terms": "off"},
+			map[string]interface{}{"terms": "accepted_if:marketing,true"},
+			false,
+		},
+		"declined-true1": {
+			map[string]interface{}{"foo": "no"},
+			map[string]interface{}{"foo": "declined"},
+			true,
+		},
+		"declined-true2": {
+			map[string]interface{}{"foo": "off"},
+			map[string]interface{}{"foo": "declined"},
+			true,
+		},
+		"declined-true3": {
+			map[string]interface{}{"foo": "0"},
+			map[string]interface{}{"foo": "declined"},
+			true,
+		},
+		"declined-true4": {
+			map[string]interface{}{"foo": 0},
+			map[string]interface{}{"foo": "declined"},
+			true,
+		},
+		"declined-true5": {
+			map[string]interface{}{"foo": false},
+			map[string]interface{}{"foo": "declined"},
+			true,
+		},
+		"declined-false1": {
+			map[string]interface{}{"foo": "yes"},
+			map[string]interface{}{"foo": "declined"},
+			false,
+		},
+		"declined-false2": {
+			map[string]interface{}{},
+			map[string]interface{}{"foo": "declined"},
+			false,
+		},
+		"declined_if-true1": {
+			map[string]interface{}{"age": 21},
+			map[string]interface{}{"ads": "declined_if:age,15"},
+			true,
+		},
+		"declined_if-true2": {
+			map[string]interface{}{"age": 15, "ads": false},
+			map[string]interface{}{"ads": "declined_if:age,15"},
+			true,
+		},
+		"declined_if-false1": {
+			map[string]interface{}{"age": 15},
+			map[string]interface{}{"ads": "declined_if:age,15"},
+			false,
+		},
+
 		// alpha rule
 		// ======================================================
 		"alpha-true1": {
@@ -278,6 +414,78 @@ func TestRules(t *testing.T) {
 			false,
 		},
 
+		// in_array rule
+		// ======================================================
+		"in_array-true1": {
+			map[string]interface{}{"foo": "a", "bar": []interface{}{"a", "b"}},
+			map[string]interface{}{"foo": "in_array:bar.*"},
+			true,
+		},
+		"in_array-true2": {
+			map[string]interface{}{"foo": 2, "bar": []int{1, 2}},
+			map[string]interface{}{"foo": "in_array:bar.*"},
+			true,
+		},
+
+		"in_array-false1": {
+			map[string]interface{}{"foo": "c", "bar": []interface{}{"a", "b"}},
+			map[string]interface{}{"foo": "in_array:bar.*"},
+			false,
+		},
+		"in_array-false2": {
+			map[string]interface{}{"foo": "a", "bar": "a"},
+			map[string]interface{}{"foo": "in_array:bar.*"},
+			false,
+		},
+		"in_array-false3": {
+			map[string]interface{}{"foo": "a"},
+			map[string]interface{}{"foo": "in_array:bar.*"},
+			false,
+		},
+
+		// in_ci rule
+		// ======================================================
+		"in_ci-true1": {
+			map[string]interface{}{"foo": "A"},
+			map[string]interface{}{"foo": "in_ci:a,b"},
+			true,
+		},
+		"in_ci-true2": {
+			map[string]interface{}{"foo": "b"},
+			map[string]interface{}{"foo": "in_ci:A,B"},
+			true,
+		},
+
+		"in_ci-false1": {
+			map[string]interface{}{"foo": "c"},
+			map[string]interface{}{"foo": "in_ci:a,b"},
+			false,
+		},
+
+		// integer rule
+		// ======================================================
+		"integer-true1": {
+			map[string]interface{}{"foo": 3},
+			map[string]interface{}{"foo": "integer"},
+			true,
+		},
+		"integer-true2": {
+			map[string]interface{}{"foo": 3.0},
+			map[string]interface{}{"foo": "integer"},
+			true,
+		},
+
+		"integer-false1": {
+			map[string]interface{}{"foo": 3.1},
+			map[string]interface{}{"foo": "integer"},
+			false,
+		},
+		"integer-false2": {
+			map[string]interface{}{"foo": "3"},
+			map[string]interface{}{"foo": "integer"},
+			false,
+		},
+
 		// max rule
 		// ======================================================
 		"max-true1": {
@@ -300,6 +508,11 @@ func TestRules(t *testing.T) {
 			map[string]interface{}{"foo": "max:3"},
 			true,
 		},
+		"max-true5": {
+			map[string]interface{}{"foo": "héllo"},
+			map[string]interface{}{"foo": "max:5"},
+			true,
+		},
 
 		"max-false1": {
 			map[string]interface{}{"foo": 3},
@@ -366,6 +579,44 @@ func TestRules(t *testing.T) {
 			false,
 		},
 
+		// not_in rule
+		// ======================================================
+		"not_in-true1": {
+			map[string]interface{}{"foo": 3},
+			map[string]interface{}{"foo": "not_in:1,2"},
+			true,
+		},
+		"not_in-true2": {
+			map[string]interface{}{"foo": "A"},
+			map[string]interface{}{"foo": "not_in:a,b"},
+			true,
+		},
+
+		"not_in-false1": {
+			map[string]interface{}{"foo": 1},
+			map[string]interface{}{"foo": "not_in:1,2"},
+			false,
+		},
+		"not_in-false2": {
+			map[string]interface{}{"foo": true},
+			map[string]interface{}{"foo": "not_in:1,2"},
+			false,
+		},
+
+		// not_in_ci rule
+		// ======================================================
+		"not_in_ci-true1": {
+			map[string]interface{}{"foo": "c"},
+			map[string]interface{}{"foo": "not_in_ci:a,b"},
+			true,
+		},
+
+		"not_in_ci-false1": {
+			map[string]interface{}{"foo": "A"},
+			map[string]interface{}{"foo": "not_in_ci:a,b"},
+			false,
+		},
+
 		// num rule
 		// ======================================================
 		"num-true1": {
@@ -405,6 +656,99 @@ func TestRules(t *testing.T) {
 			false,
 		},
 
+		// prohibited and exclusion rules
+		// ======================================================
+		"prohibited-true1": {
+			map[string]interface{}{},
+			map[string]interface{}{"foo": "prohibited"},
+			true,
+		},
+		"prohibited-true2": {
+			map[string]interface{}{"foo": " "},
+			map[string]interface{}{"foo": "prohibited"},
+			true,
+		},
+		"prohibited-true3": {
+			map[string]interface{}{"foo": []interface{}{}},
+			map[string]interface{}{"foo": "prohibited"},
+			true,
+		},
+		"prohibited-false1": {
+			map[string]interface{}{"foo": "a"},
+			map[string]interface{}{"foo": "prohibited"},
+			false,
+		},
+		"prohibited-false2": {
+			map[string]interface{}{"foo": false},
+			map[string]interface{}{"foo": "prohibited"},
+			false,
+		},
+		"prohibited_if-true1": {
+			map[string]interface{}{"role": "admin", "foo": "a"},
+			map[string]interface{}{"foo": "prohibited_if:role,guest,anonymous"},
+			true,
+		},
+		"prohibited_if-true2": {
+			map[string]interface{}{"role": "guest"},
+			map[string]interface{}{"foo": "prohibited_if:role,guest,anonymous"},
+			true,
+		},
+		"prohibited_if-false1": {
+			map[string]interface{}{"role": "anonymous", "foo": "a"},
+			map[string]interface{}{"foo": "prohibited_if:role,guest,anonymous"},
+			false,
+		},
+		"prohibited_unless-true1": {
+			map[string]interface{}{"role": "admin", "foo": "a"},
+			map[string]interface{}{"foo": "prohibited_unless:role,admin"},
+			true,
+		},
+		"prohibited_unless-true2": {
+			map[string]interface{}{"foo": ""},
+			map[string]interface{}{"foo": "prohibited_unless:role,admin"},
+			true,
+		},
+		"prohibited_unless-false1": {
+			map[string]interface{}{"foo": "a"},
+			map[string]interface{}{"foo": "prohibited_unless:role,admin"},
+			false,
+		},
+		"prohibits-true1": {
+			map[string]interface{}{"foo": "a"},
+			map[string]interface{}{"foo": "prohibits:bar,baz"},
+			true,
+		},
+		"prohibits-true2": {
+			map[string]interface{}{"bar": "b"},
+			map[string]interface{}{"foo": "prohibits:bar,baz"},
+			true,
+		},
+		"prohibits-false1": {
+			map[string]interface{}{"foo": "a", "baz": 1},
+			map[string]interface{}{"foo": "prohibits:bar,baz"},
+			false,
+		},
+		"exclude_if-true1": {
+			map[string]interface{}{"type": "guest", "foo": 1},
+			map[string]interface{}{"foo": "exclude_if:type,guest|string"},
+			true,
+		},
+		"exclude_if-false1": {
+			map[string]interface{}{"type": "user", "foo": 1},
+			map[string]interface{}{"foo": "exclude_if:type,guest|string"},
+			false,
+		},
+		"exclude_unless-true1": {
+			map[string]interface{}{"type": "guest"},
+			map[string]interface{}{"foo": "required|exclude_unless:type,user"},
+			true,
+		},
+		"exclude_unless-false1": {
+			map[string]interface{}{"type": "user"},
+			map[string]interface{}{"foo": "required|exclude_unless:type,user"},
+			false,
+		},
+
 		// regex rule
 		// ======================================================
 		"regex-true1": {
@@ -467,6 +811,51 @@ func TestRules(t *testing.T) {
 			false,
 		},
 
+		// required_with rule
+		// ======================================================
+		"required_with-true1": {
+			map[string]interface{}{"foo": "a", "bar": "b"},
+			map[string]interface{}{"foo": "required_with:bar"},
+			true,
+		},
+		"required_with-true2": {
+			map[string]interface{}{"baz": "c"},
+			map[string]interface{}{"foo": "required_with:bar"},
+			true,
+		},
+		"required_with-true3": {
+			map[string]interface{}{"bar": ""},
+			map[string]interface{}{"foo": "required_with:bar,baz"},
+			true,
+		},
+		"required_with-true4": {
+			map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"id": 1, "name": "a"},
+				map[string]interface{}{"note": "b"},
+			}},
+			map[string]interface{}{"items.*.name": "required_with:items.*.id"},
+			true,
+		},
+
+		"required_with-false1": {
+			map[string]interface{}{"bar": "b"},
+			map[string]interface{}{"foo": "required_with:bar"},
+			false,
+		},
+		"required_with-false2": {
+			map[string]interface{}{"foo": " ", "baz": "c"},
+			map[string]interface{}{"foo": "required_with:bar,baz"},
+			false,
+		},
+		"required_with-false3": {
+			map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"id": 1, "name": "a"},
+				map[string]interface{}{"id": 2},
+			}},
+			map[string]interface{}{"items.*.name": "required_with:items.*.id"},
+			false,
+		},
+
 		// size rule
 		// ======================================================
 		"size-true1": {
@@ -574,6 +963,56 @@ func TestRules(t *testing.T) {
 			map[string]interface{}{"foo": "string|max:2"},
 			false,
 		},
+
+		// ======================================================
+		// nested attributes
+		// ======================================================
+		"nested-true1": {
+			map[string]interface{}{"foo": map[string]interface{}{"bar": "aPz"}},
+			map[string]interface{}{"foo.bar": "required|alpha"},
+			true,
+		},
+		"nested-true2": {
+			map[string]interface{}{"foo": []interface{}{1, 2}},
+			map[string]interface{}{"foo.*": "required|max:2"},
+			true,
+		},
+		"nested-true3": {
+			map[string]interface{}{"foo": []interface{}{map[string]interface{}{"bar": "a"}}},
+			map[string]interface{}{"foo.*.bar": "required|alpha", "foo.0.bar": "size:1"},
+			true,
+		},
+		"nested-true4": {
+			map[string]interface{}{},
+			map[string]interface{}{"foo.*": "required"},
+			true,
+		},
+		"nested-true5": {
+			map[string]interface{}{"foo.bar": "aPz", "foo": map[string]interface{}{"bar": "09"}},
+			map[string]interface{}{"foo.bar": "required|alpha"},
+			true,
+		},
+
+		"nested-false1": {
+			map[string]interface{}{"foo": map[string]interface{}{"bar": "09"}},
+			map[string]interface{}{"foo.bar": "required|alpha"},
+			false,
+		},
+		"nested-false2": {
+			map[string]interface{}{"foo": map[string]interface{}{}},
+			map[string]interface{}{"foo.bar": "required"},
+			false,
+		},
+		"nested-false3": {
+			map[string]interface{}{"foo": []interface{}{1, 2, 3}},
+			map[string]interface{}{"foo.*": "required|max:2"},
+			false,
+		},
+		"nested-false4": {
+			map[string]interface{}{"foo": []interface{}{map[string]interface{}{"bar": "a"}, map[string]interface{}{}}},
+			map[string]interface{}{"foo.*.bar": "required|alpha"},
+			false,
+		},
 	}
 
 	for i, tt := range tests {
@@ -583,3 +1022,229 @@ func TestRules(t *testing.T) {
 		}
 	}
 }
+
+type color string
+
+func (color) Values() []string {
+	return []string{"red", "green, light"}
+}
+
+func TestListRules(t *testing.T) {
+	tests := map[string]struct {
+		value interface{}
+		rule  string
+		pass  bool
+	}{
+		"in-slice":        {"b", In([]string{"a", "b"}), true},
+		"in-ints":         {2, In([]int{1, 2}), true},
+		"in-comma":        {"a,b", In([]string{"a,b", "c"}), true},
+		"in-comma-false":  {"a", In([]string{"a,b", "c"}), false},
+		"in-pipe":         {"a|b", "required|" + In([]string{"a|b"}), true},
+		"in-valuer":       {"green, light", In(color("")), true},
+		"in-valuer-false": {"blue", In(color("")), false},
+		"not_in-slice":    {"c", NotIn([]string{"a", "b"}), true},
+		"not_in-valuer":   {color("red"), NotIn(color("")), false},
+	}
+
+	for i, tt := range tests {
+		validator := New(map[string]interface{}{"foo": tt.value}, map[string]interface{}{"foo": tt.rule})
+		if validator.Passes() != tt.pass {
+			t.Errorf("Test %s failed", i)
+		}
+	}
+}
+
+func TestConsentMessages(t *testing.T) {
+	validator := New(
+		map[string]interface{}{"type": "personal"},
+		map[string]interface{}{"terms": "accepted_if:type,personal"},
+	)
+	if message := validator.GetMessage(); message != "The terms must be accepted when type is personal." {
+		t.Errorf("unexpected message %q", message)
+	}
+}
+
+func TestProhibitedMessages(t *testing.T) {
+	errs := New(
+		map[string]interface{}{"foo": "a", "bar": "b", "baz": "c"},
+		map[string]interface{}{"foo": "prohibits:bar,baz", "bar": "prohibited_unless:role,admin,owner"},
+	).Errors()
+	if len(errs) != 2 ||
+		errs[0].Message != "The bar field is prohibited unless role is in admin, owner." ||
+		errs[1].Message != "The foo field prohibits bar, baz from being present." {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestValidated(t *testing.T) {
+	data := map[string]interface{}{
+		"name":    "Ann",
+		"type":    "personal",
+		"company": "ACME",
+		"other":   "x",
+		"address": map[string]interface{}{"city": "Oslo", "zip": "0150"},
+		"items": []interface{}{
+			map[string]interface{}{"id": 1, "note": "a"},
+			map[string]interface{}{"id": 2},
+		},
+	}
+	rules := map[string]interface{}{
+		"name":         "required|string",
+		"company":      "exclude_if:type,personal|required|string",
+		"address.city": "string",
+		"items.*.id":   "integer",
+		"missing":      "string",
+	}
+
+	for _, concurrency := range []int{1, 4} {
+		validated, err := New(data, rules, WithConcurrency(concurrency)).Validated()
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		expected := map[string]interface{}{
+			"name":    "Ann",
+			"address": map[string]interface{}{"city": "Oslo"},
+			"items": []interface{}{
+				map[string]interface{}{"id": 1},
+				map[string]interface{}{"id": 2},
+			},
+		}
+		if !reflect.DeepEqual(validated, expected) {
+			t.Errorf("expected %v, got %v", expected, validated)
+		}
+	}
+
+	// An excluded attribute is dropped from an ancestor with rules.
+	nested := map[string]interface{}{
+		"role": "guest",
+		"a":    map[string]interface{}{"b": 1, "c": 2},
+		"list": []interface{}{"x", "y", "z"},
+	}
+	nestedRules := map[string]interface{}{
+		"a":      "required",
+		"a.b":    "exclude_if:role,guest",
+		"list":   "required",
+		"list.*": "exclude_if:role,guest",
+	}
+	for _, concurrency := range []int{1, 4} {
+		validated, err := New(nested, nestedRules, WithConcurrency(concurrency)).Validated()
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		expected := map[string]interface{}{"a": map[string]interface{}{"c": 2}, "list": []interface{}{}}
+		if !reflect.DeepEqual(validated, expected) {
+			t.Errorf("expected %v, got %v", expected, validated)
+		}
+	}
+	if len(nested["a"].(map[string]interface{})) != 2 || len(nested["list"].([]interface{})) != 3 {
+		t.Errorf("expected the data to be left as is, got %v", nested)
+	}
+
+	if _, err := New(data, map[string]interface{}{"other": "integer"}).Validated(); err == nil {
+		t.Errorf("expected an error for invalid data")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	err := New(
+		map[string]interface{}{"foo": "aPz"},
+		map[string]interface{}{"foo": "required|string|max:5"},
+	).Validate()
+	if err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+
+	err = New(
+		map[string]interface{}{"foo": "aPz", "bar": "b"},
+		map[string]interface{}{"foo": "required|string|max:2", "bar": "in:a"},
+	).Validate()
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 validation errors, got %v", err)
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Rule == "" {
+		t.Errorf("expected a field error, got %v", err)
+	}
+
+	err = New(
+		map[string]interface{}{"foo": "aPz"},
+		map[string]interface{}{"foo": "unknown"},
+	).Validate()
+	if !errors.Is(err, ErrRuleNotSupported) {
+		t.Errorf("expected ErrRuleNotSupported, got %v", err)
+	}
+
+	err = New(
+		map[string]interface{}{"foo": "aPz"},
+		map[string]interface{}{"foo": "max:a"},
+	).Validate()
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	data := map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4}
+
+	for i := 0; i < 20; i++ {
+		validator := New(data, map[string]interface{}{"d": "string", "b": "string", "c": "string"})
+		if validator.Passes() || validator.GetMessage() != "The b must be a string." {
+			t.Fatalf("expected the sorted first message, got %q", validator.GetMessage())
+		}
+
+		errs := validator.Errors()
+		if len(errs) != 3 || errs[0].Field != "b" || errs[1].Field != "c" || errs[2].Field != "d" {
+			t.Fatalf("expected errors in sorted order, got %v", errs)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		validator := NewOrdered(data, []AttributeRules{
+			{"d", "string"},
+			{"b", []string{"string"}},
+			{"c", "required|string"},
+		})
+		if validator.Passes() || validator.GetMessage() != "The d must be a string." {
+			t.Fatalf("expected the declared first message, got %q", validator.GetMessage())
+		}
+
+		errs := validator.Errors()
+		if len(errs) != 3 || errs[0].Field != "d" || errs[1].Field != "b" || errs[2].Field != "c" {
+			t.Fatalf("expected errors in declaration order, got %v", errs)
+		}
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	validator := New(
+		map[string]interface{}{"foo": "aPz", "bar": 3},
+		map[string]interface{}{"foo": "required|string|max:2", "bar": "in:1,2", "baz": "required"},
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if validator.Passes() {
+				t.Error("expected validation to fail")
+			}
+			if validator.GetMessage() != "The bar field must one of (1,2)." {
+				t.Errorf("unexpected message %q", validator.GetMessage())
+			}
+			if errs := validator.Errors(); len(errs) != 3 {
+				t.Errorf("expected 3 errors, got %d", len(errs))
+			}
+			if err := validator.Validate(); err == nil {
+				t.Error("expected an error")
+			}
+		}()
+	}
+	wg.Wait()
+}
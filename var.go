@@ -1,39 +1,192 @@
 package validation
 
 import (
-	"errors"
+	"fmt"
 )
 
-type ruleMethod func(string, interface{}, []string) bool
+type ruleMethod func(*Context) bool
 
 var ruleMethodMap = map[string]ruleMethod{
-	"alpha":     validateAlpha,
-	"alpha_num": validateAlphaNum,
-	"between":   validateBetween,
-	"bool":      validateBool,
-	"email":     validateEmail,
-	"float":     validateFloat,
-	"in":        validateIn,
-	"max":       validateMax,
-	"min":       validateMin,
-	"num":       validateNum,
-	"regex":     validateRegex,
-	"required":  validateRequired,
-	"size":      validateSize,
-	"string":    validateString,
+	"accepted":          validateAccepted,
+	"accepted_if":       validateAcceptedIf,
+	"alpha":             validateAlpha,
+	"alpha_num":         validateAlphaNum,
+	"between":           validateBetween,
+	"bic":               validateBic,
+	"bool":              validateBool,
+	"country":           validateCountry,
+	"credit_card":       validateCreditCard,
+	"currency":          validateCurrency,
+	"declined":          validateDeclined,
+	"declined_if":       validateDeclinedIf,
+	"ean":               validateEan,
+	"email":             validateEmail,
+	"exclude_if":        excludeIf,
+	"exclude_unless":    excludeUnless,
+	"float":             validateFloat,
+	"geojson":           validateGeojson,
+	"iban":              validateIban,
+	"in":                validateIn,
+	"in_array":          validateInArray,
+	"in_ci":             validateInCi,
+	"integer":           validateInteger,
+	"isbn":              validateIsbn,
+	"language":          validateLanguage,
+	"lat_lng":           validateLatLng,
+	"latitude":          validateLatitude,
+	"longitude":         validateLongitude,
+	"max":               validateMax,
+	"min":               validateMin,
+	"not_in":            validateNotIn,
+	"not_in_ci":         validateNotInCi,
+	"num":               validateNum,
+	"password":          validatePassword,
+	"phone":             validatePhone,
+	"prohibited":        validateProhibited,
+	"prohibited_if":     validateProhibitedIf,
+	"prohibited_unless": validateProhibitedUnless,
+	"prohibits":         validateProhibits,
+	"regex":             validateRegex,
+	"required":          validateRequired,
+	"required_with":     validateRequiredWith,
+	"size":              validateSize,
+	"string":            validateString,
+	"timezone":          validateTimezone,
+	"upc":               validateUpc,
+	"within_bbox":       validateWithinBbox,
+}
+
+// requirementRuleMap holds the rules that report each unmet requirement as a
+// separate failure. Their methods in ruleMethodMap pass when all requirements
+// are met.
+var requirementRuleMap = map[string]func(*Context) []string{
+	"password": passwordRequirements,
+}
+
+// normalizerRuleMap holds the rules that convert a valid value to a normal
+// form, such as an E.164 phone number, returned by Validated with
+// WithNormalization.
+var normalizerRuleMap = map[string]func(*Context) (interface{}, bool){
+	"phone": normalizePhoneValue,
+}
+
+// sensitiveRules are the rules of secret or personal values, such as
+// passwords and card numbers. The values of attributes with one of these
+// rules are not kept as samples by a Report.
+var sensitiveRules = map[string]bool{
+	"credit_card": true,
+	"iban":        true,
+	"password":    true,
+}
+
+// implicitRules are the rules that apply to missing values.
+var implicitRules = map[string]bool{
+	"accepted":          true,
+	"accepted_if":       true,
+	"declined":          true,
+	"declined_if":       true,
+	"prohibited":        true,
+	"prohibited_if":     true,
+	"prohibited_unless": true,
+	"required":          true,
+	"required_with":     true,
+}
+
+// exclusionRules are the rules whose method reports whether the attribute is
+// excluded, that is neither validated nor part of the validated data.
+var exclusionRules = map[string]bool{
+	"exclude_if":     true,
+	"exclude_unless": true,
+}
+
+// ruleParameterCounts are the minimum numbers of parameters of the rules
+// that take parameters.
+var ruleParameterCounts = map[string]int{
+	"accepted_if":       2,
+	"between":           2,
+	"declined_if":       2,
+	"exclude_if":        2,
+	"exclude_unless":    2,
+	"in":                1,
+	"in_array":          1,
+	"in_ci":             1,
+	"max":               1,
+	"min":               1,
+	"not_in":            1,
+	"not_in_ci":         1,
+	"prohibited_if":     2,
+	"prohibited_unless": 2,
+	"prohibits":         1,
+	"regex":             1,
+	"required_with":     1,
+	"size":              1,
+	"within_bbox":       4,
+}
+
+// ruleParameterCheckers check the parameters of the rules whose parameters
+// are more than attribute names and values, as the rules do before looking
+// at the value. The rules that are neither here nor in ruleParameterCounts
+// take no parameters.
+var ruleParameterCheckers = map[string]func([]string) error{
+	"country":     checkCountryParameters,
+	"credit_card": checkCreditCardParameters,
+	"ean":         checkEanParameters,
+	"geojson":     checkGeojsonParameters,
+	"isbn":        checkIsbnParameters,
+	"password":    checkPasswordParameters,
+	"phone":       checkPhoneParameters,
+	"within_bbox": checkWithinBboxParameters,
 }
 
 var defaultRuleMessages = map[string]string{
-	"alpha":     "The :attribute may only contain letters.",
-	"alpha_num": "The :attribute may only contain letters and numbers.",
-	"bool":      "The :attribute field must be true or false.",
-	"email":     "The :attribute must be a valid email address.",
-	"float":     "The :attribute must be a float.",
-	"in":        "The :attribute field must one of (:values).",
-	"num":       "The :attribute may only contain numbers.",
-	"regex":     "The :attribute format is invalid.",
-	"required":  "The :attribute field is required.",
-	"string":    "The :attribute must be a string.",
+	"accepted":               "The :attribute must be accepted.",
+	"accepted_if":            "The :attribute must be accepted when :other is :value.",
+	"alpha":                  "The :attribute may only contain letters.",
+	"alpha_num":              "The :attribute may only contain letters and numbers.",
+	"bic":                    "The :attribute must be a valid BIC.",
+	"bool":                   "The :attribute field must be true or false.",
+	"country":                "The :attribute must be a valid country code.",
+	"credit_card":            "The :attribute must be a valid credit card number.",
+	"currency":               "The :attribute must be a valid currency code.",
+	"custom":                 "The :attribute is invalid.",
+	"declined":               "The :attribute must be declined.",
+	"declined_if":            "The :attribute must be declined when :other is :value.",
+	"ean":                    "The :attribute must be a valid EAN.",
+	"email":                  "The :attribute must be a valid email address.",
+	"float":                  "The :attribute must be a float.",
+	"geojson":                "The :attribute must be a valid GeoJSON geometry.",
+	"iban":                   "The :attribute must be a valid IBAN.",
+	"in":                     "The :attribute field must one of (:values).",
+	"in_array":               "The :attribute field must exist in :other.",
+	"in_ci":                  "The :attribute field must one of (:values).",
+	"integer":                "The :attribute must be an integer.",
+	"isbn":                   "The :attribute must be a valid ISBN.",
+	"language":               "The :attribute must be a valid language tag.",
+	"lat_lng":                "The :attribute must be a valid latitude and longitude pair.",
+	"latitude":               "The :attribute must be a latitude between -90 and 90.",
+	"longitude":              "The :attribute must be a longitude between -180 and 180.",
+	"not_in":                 "The selected :attribute is invalid.",
+	"not_in_ci":              "The selected :attribute is invalid.",
+	"num":                    "The :attribute may only contain numbers.",
+	"password:letters":       "The :attribute must contain at least one letter.",
+	"password:min":           "The :attribute must be at least :min characters.",
+	"password:mixed":         "The :attribute must contain at least one uppercase and one lowercase letter.",
+	"password:numbers":       "The :attribute must contain at least one number.",
+	"password:string":        "The :attribute must be a string.",
+	"password:symbols":       "The :attribute must contain at least one symbol.",
+	"password:uncompromised": "The given :attribute has appeared in a data leak. Please choose a different :attribute.",
+	"phone":                  "The :attribute must be a valid phone number.",
+	"prohibited":             "The :attribute field is prohibited.",
+	"prohibited_if":          "The :attribute field is prohibited when :other is :value.",
+	"prohibited_unless":      "The :attribute field is prohibited unless :other is in :values.",
+	"prohibits":              "The :attribute field prohibits :other from being present.",
+	"regex":                  "The :attribute format is invalid.",
+	"required":               "The :attribute field is required.",
+	"required_with":          "The :attribute field is required when :values is present.",
+	"string":                 "The :attribute must be a string.",
+	"timezone":               "The :attribute must be a valid time zone.",
+	"upc":                    "The :attribute must be a valid UPC.",
+	"within_bbox":            "The :attribute must be within the bounding box :min_lat,:min_lng,:max_lat,:max_lng.",
 }
 
 var defaultRuleMessages2 = map[string]map[string]string{
@@ -58,7 +211,7 @@ var defaultRuleMessages2 = map[string]map[string]string{
 func getRuleMethod(rule string) (ruleMethod, error) {
 	method, ok := ruleMethodMap[rule]
 	if !ok {
-		return nil, errors.New("validation: rule " + rule + " not supported.")
+		return nil, fmt.Errorf("%w: %s", ErrRuleNotSupported, rule)
 	}
 
 	return method, nil